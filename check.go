@@ -0,0 +1,241 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/oliviernguyenquoc/oapisqlc/dbSchema"
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+)
+
+// checkSchemaDrift regenerates the DDL of an OpenAPI spec and compares it to a committed SQL script.
+// Both scripts are compared statement by statement once deparsed, and table by table; the returned
+// differences are empty and sameStatements is true when the committed script is up to date.
+func checkSchemaDrift(openAPISpec []byte, committedSQL string, flags Flags) (differences []dbSchema.Difference, sameStatements bool, err error) {
+	doc, err := parseOpenAPISpec(openAPISpec)
+	if err != nil {
+		return nil, false, err
+	}
+
+	tableDefinitions, err := buildTables(doc.Components, flags)
+	if err != nil {
+		return nil, false, err
+	}
+
+	generatedSQL, err := fromComponentsToSQL(tableDefinitions, flags)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Unlike fingerprints, deparsed statements keep the constants, e.g. CHECK bounds and type modifiers
+	generatedStatements, err := deparseSQL(generatedSQL)
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot parse generated SQL: %v", err)
+	}
+
+	committedStatements, err := deparseSQL(committedSQL)
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot parse committed SQL: %v", err)
+	}
+
+	generatedTables, err := dbSchema.ParseSQLTables(generatedSQL)
+	if err != nil {
+		return nil, false, fmt.Errorf("generated SQL: %v", err)
+	}
+
+	committedTables, err := dbSchema.ParseSQLTables(committedSQL)
+	if err != nil {
+		return nil, false, fmt.Errorf("committed SQL: %v", err)
+	}
+
+	return dbSchema.DiffTables(generatedTables, committedTables), generatedStatements == committedStatements, nil
+}
+
+// deparseSQL parses and deparses a script, giving the same text to equivalent statements.
+// The indentation of function bodies is ignored.
+func deparseSQL(sql string) (string, error) {
+	tree, err := pg_query.Parse(sql)
+	if err != nil {
+		return "", err
+	}
+
+	for _, rawStmt := range tree.Stmts {
+		function := rawStmt.Stmt.GetCreateFunctionStmt()
+		if function == nil {
+			continue
+		}
+		for _, option := range function.Options {
+			if defElem := option.GetDefElem(); defElem != nil && defElem.Defname == "as" {
+				for _, item := range defElem.Arg.GetList().GetItems() {
+					if body := item.GetString_(); body != nil {
+						body.Sval = strings.Join(strings.Fields(body.Sval), " ")
+					}
+				}
+			}
+		}
+	}
+
+	return pg_query.Deparse(tree)
+}
+
+// printDriftReport writes the differences grouped by table
+func printDriftReport(differences []dbSchema.Difference, sameStatements bool) {
+	if !sameStatements {
+		fmt.Println("Generated SQL and committed SQL have different statements.")
+	}
+
+	var currentTable string
+	for _, difference := range differences {
+		if difference.Column == "" && difference.Property == "" {
+			fmt.Printf("- %s\n", difference)
+			currentTable = ""
+			continue
+		}
+
+		if difference.Table != currentTable {
+			fmt.Printf("table %s:\n", difference.Table)
+			currentTable = difference.Table
+		}
+		fmt.Printf("  - %s\n", difference)
+	}
+}
+
+// runCheck implements the `check` command and returns the process exit code
+func runCheck(args []string) int {
+	checkFlags := flag.NewFlagSet("check", flag.ExitOnError)
+	against := checkFlags.String("against", "", "Path to the committed SQL schema file")
+	deleteStatements := checkFlags.Bool("deleteStatements", false, "The committed file contains delete statements")
+	configPath := checkFlags.String("config", "", "Path to a YAML configuration file")
+	checkFlags.Parse(args)
+
+	if *against == "" || checkFlags.NArg() != 1 {
+		fmt.Println("Usage: oapisqlc check --against <path_to_sql_file> [--config <path_to_config_file>] <path_to_yaml_file>")
+		return 1
+	}
+
+	options, err := loadOptions(*configPath)
+	if err != nil {
+		fmt.Printf("Failed to load configuration: %v\n", err)
+		return 1
+	}
+
+	openAPISpec, err := os.ReadFile(checkFlags.Arg(0))
+	if err != nil {
+		fmt.Printf("Failed to read OpenAPI spec: %v\n", err)
+		return 1
+	}
+
+	committedSQL, err := os.ReadFile(*against)
+	if err != nil {
+		fmt.Printf("Failed to read committed SQL: %v\n", err)
+		return 1
+	}
+
+	differences, sameStatements, err := checkSchemaDrift(openAPISpec, string(committedSQL), Flags{deleteStatements: *deleteStatements, options: options})
+	if err != nil {
+		fmt.Printf("Failed to check schema: %v\n", err)
+		return 1
+	}
+
+	if sameStatements && len(differences) == 0 {
+		fmt.Printf("%s is up to date\n", *against)
+		return 0
+	}
+
+	fmt.Printf("%s is out of date:\n", *against)
+	printDriftReport(differences, sameStatements)
+	return 1
+}
+
+// compareWithDatabaseDump builds the tables of an OpenAPI spec and compares them to the tables of a
+// database schema dump. It returns the differences and the statements reconciling the database with the spec.
+func compareWithDatabaseDump(openAPISpec []byte, dumpSQL string, options dbSchema.Options) (differences []dbSchema.Difference, alterStatements []string, err error) {
+	doc, err := parseOpenAPISpec(openAPISpec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	flags := Flags{options: options}
+	tableDefinitions, err := buildTables(doc.Components, flags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	generatedSQL, err := fromComponentsToSQL(tableDefinitions, flags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	expectedTables, err := dbSchema.ParseSQLTables(generatedSQL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generated SQL: %v", err)
+	}
+
+	actualTables, err := dbSchema.ParseSQLTables(dumpSQL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("database dump: %v", err)
+	}
+
+	alterStatements, err = dbSchema.ReconcileSQLStatements(expectedTables, actualTables)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return dbSchema.DiffTables(expectedTables, actualTables), alterStatements, nil
+}
+
+// runCompare implements the `compare` command and returns the process exit code
+func runCompare(args []string) int {
+	compareFlags := flag.NewFlagSet("compare", flag.ExitOnError)
+	dump := compareFlags.String("dump", "", "Path to the database schema dump (pg_dump --schema-only)")
+	alterStatements := compareFlags.Bool("alterStatements", false, "Print the statements reconciling the database with the spec")
+	configPath := compareFlags.String("config", "", "Path to a YAML configuration file")
+	compareFlags.Parse(args)
+
+	if *dump == "" || compareFlags.NArg() != 1 {
+		fmt.Println("Usage: oapisqlc compare --dump <path_to_sql_dump> [--alterStatements] [--config <path_to_config_file>] <path_to_yaml_file>")
+		return 1
+	}
+
+	options, err := loadOptions(*configPath)
+	if err != nil {
+		fmt.Printf("Failed to load configuration: %v\n", err)
+		return 1
+	}
+
+	openAPISpec, err := os.ReadFile(compareFlags.Arg(0))
+	if err != nil {
+		fmt.Printf("Failed to read OpenAPI spec: %v\n", err)
+		return 1
+	}
+
+	dumpSQL, err := os.ReadFile(*dump)
+	if err != nil {
+		fmt.Printf("Failed to read database dump: %v\n", err)
+		return 1
+	}
+
+	differences, statements, err := compareWithDatabaseDump(openAPISpec, string(dumpSQL), options)
+	if err != nil {
+		fmt.Printf("Failed to compare schema: %v\n", err)
+		return 1
+	}
+
+	if len(differences) == 0 {
+		fmt.Printf("%s matches the spec\n", *dump)
+		return 0
+	}
+
+	fmt.Printf("%s does not match the spec:\n", *dump)
+	printDriftReport(differences, true)
+
+	if *alterStatements {
+		fmt.Print("\nStatements reconciling the database with the spec:\n\n")
+		for _, statement := range statements {
+			fmt.Println(statement)
+		}
+	}
+	return 1
+}
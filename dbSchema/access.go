@@ -0,0 +1,94 @@
+package dbSchema
+
+import (
+	"fmt"
+	"strings"
+
+	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// isDatabaseColumn tells if a property is stored in the database. Computed or transient properties are
+// skipped with x-database-column: false, or x-database-entity: false on an inline property.
+func isDatabaseColumn(property *highbase.SchemaProxy) bool {
+	schema := property.Schema()
+	if val, ok := extensionValue(schema, "x-database-column"); ok && val == "false" {
+		return false
+	}
+	// On a referenced schema, x-database-entity: false means the schema is stored in the column
+	if val, ok := extensionValue(schema, "x-database-entity"); ok && val == "false" && property.GetReference() == "" {
+		return false
+	}
+	return true
+}
+
+// InsertColumns returns the columns given as parameters of an INSERT. readOnly and generated columns are
+// set by the database.
+func (t Table) InsertColumns() []string {
+	var columns []string
+	for _, column := range t.ColumnDefinition {
+		if !column.ReadOnly && column.Generated == "" {
+			columns = append(columns, column.Name)
+		}
+	}
+	return columns
+}
+
+// SelectColumns returns the columns returned by a SELECT. writeOnly columns, like password hashes, are never read
+// back, and the search_vector of a full-text search is only used to search.
+func (t Table) SelectColumns() []string {
+	var columns []string
+	for _, column := range t.ColumnDefinition {
+		if !column.WriteOnly && !(t.FullTextLanguage != "" && column.Name == searchVectorColumn) {
+			columns = append(columns, column.Name)
+		}
+	}
+	return columns
+}
+
+// InsertQuery returns the sqlc query inserting a row in the table, with a parameter per INSERT column,
+// or "" when every column is set by the database
+func (t Table) InsertQuery() string {
+	columns := t.InsertColumns()
+	if len(columns) == 0 {
+		return ""
+	}
+
+	var names, parameters []string
+	for i, column := range columns {
+		names = append(names, quoteIdentifier(column))
+		parameters = append(parameters, fmt.Sprintf("$%d", i+1))
+	}
+
+	return fmt.Sprintf("-- name: Insert%s :one\nINSERT INTO %s (%s)\nVALUES (%s)\nRETURNING %s;",
+		toPascalCase(t.Name), t.sqlName(), strings.Join(names, ", "), strings.Join(parameters, ", "), t.returningColumns())
+}
+
+// UpdateQuery returns the sqlc query updating a row of the table found by its id, with a parameter per
+// INSERT column, or "" when the table has no id or no column to update
+func (t Table) UpdateQuery() string {
+	if id := t.column("id"); id == nil || !id.PrimaryKey {
+		return ""
+	}
+
+	var assignments []string
+	for _, column := range t.InsertColumns() {
+		if column != "id" {
+			assignments = append(assignments, fmt.Sprintf("%s = $%d", quoteIdentifier(column), len(assignments)+2))
+		}
+	}
+	if len(assignments) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("-- name: Update%s :one\nUPDATE %s SET %s\nWHERE id = $1\nRETURNING %s;",
+		toPascalCase(t.Name), t.sqlName(), strings.Join(assignments, ", "), t.returningColumns())
+}
+
+// returningColumns lists the SELECT columns returned by the INSERT and UPDATE queries
+func (t Table) returningColumns() string {
+	var names []string
+	for _, column := range t.SelectColumns() {
+		names = append(names, quoteIdentifier(column))
+	}
+	return strings.Join(names, ", ")
+}
@@ -5,9 +5,6 @@ import (
 	"slices"
 	"strings"
 
-	"log/slog"
-
-	"github.com/jinzhu/inflection"
 	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
 	"github.com/pb33f/libopenapi/orderedmap"
 )
@@ -22,6 +19,17 @@ type MinMaxConstraint struct {
 	Maximum *float64
 }
 
+// ExclusiveMinMaxConstraint holds the exclusive bounds of a number, from exclusiveMinimum / exclusiveMaximum
+type ExclusiveMinMaxConstraint struct {
+	ExclusiveMinimum *float64
+	ExclusiveMaximum *float64
+}
+
+// ValuesConstraint restricts a column to the literals of a const or of a non string enum
+type ValuesConstraint struct {
+	Values []string
+}
+
 type CharLengthConstraint struct {
 	MinLength *int64
 	MaxLength *int64
@@ -31,22 +39,85 @@ type PatternConstraint struct {
 	Pattern string
 }
 
+// ArrayConstraint holds the validations of an array column
+type ArrayConstraint struct {
+	MinItems    *int64
+	MaxItems    *int64
+	UniqueItems bool
+	ItemValues  []string // Literals of the allowed items, for the items of a checked string enum
+}
+
+// FormatConstraint checks the string formats which have no dedicated PostgreSQL type
+type FormatConstraint struct {
+	Format string
+}
+
+// Regular expressions checking string formats stored as text
+var formatPatterns = map[string]string{
+	"email": `^[^@\s]+@[^@\s]+\.[^@\s]+$`,
+	"uri":   `^[a-zA-Z][a-zA-Z0-9+.-]*:\S+$`,
+}
+
+// CheckConstraint holds a raw CHECK expression, e.g. one read back from an existing SQL file
+type CheckConstraint struct {
+	Expression string
+	Name       string // Name of the constraint read back from SQL, if any
+}
+
 type Column struct {
-	Name                 string
-	DataType             string
-	DataFormat           string
-	NotNull              bool
-	DefaultValue         string
-	PrimaryKey           bool
-	MinMaxConstraint     MinMaxConstraint
-	CharLengthConstraint CharLengthConstraint
-	PatternConstraint    PatternConstraint
-	Unique               bool
-	customType           string
-	Enum                 []string
-	ForeignKey           string
+	Name                      string
+	DataType                  string
+	SQLType                   string // PostgreSQL type used as is, instead of the DataType/DataFormat mapping
+	DataFormat                string
+	NotNull                   bool
+	DefaultValue              string
+	PrimaryKey                bool
+	MinMaxConstraint          MinMaxConstraint
+	ExclusiveMinMaxConstraint ExclusiveMinMaxConstraint
+	ValuesConstraint          ValuesConstraint
+	CharLengthConstraint      CharLengthConstraint
+	PatternConstraint         PatternConstraint
+	ArrayConstraint           ArrayConstraint
+	FormatConstraint          FormatConstraint
+	MultipleOfConstraint      MultipleOfConstraint
+	Unique                    bool
+	customType                string
+	Enum                      []string
+	ForeignKey                TableReference
+	Identity                  string // ALWAYS or BY DEFAULT for an identity column
+	Generated                 string // Expression of a GENERATED ALWAYS AS ... STORED column (x-generated)
+	GinIndex                  bool   // Index the JSONB values with a GIN index (x-searchable)
+	Collation                 string // COLLATE of the column (x-collation)
+	Storage                   string // PLAIN, EXTERNAL, EXTENDED or MAIN storage of the column (x-storage)
+	Compression               string // pglz or lz4 compression of the column (x-compression)
+	ReadOnly                  bool   // readOnly: set by the database, not an INSERT parameter
+	WriteOnly                 bool   // writeOnly: e.g. a password to hash, not returned by SELECT queries
+	Description               string // Title and description of the property, used in the column comment
+	Deprecated                bool   // deprecated: true, flagged in the column comment
+	Constraints               []Constraint
+	prerequisites             []string          // Statements the column definition depends on, e.g. helper functions
+	constraintNames           map[string]string // Names of the constraints read back from SQL, by column property
+	setOnUpdate               bool              // Set to NOW() by a trigger on every update (onUpdate audit column)
 }
 
+// Primitive OpenAPI types which can be the items of a native PostgreSQL array
+var arrayItemTypes = []string{"string", "integer", "number", "boolean"}
+
+// arrayHasUniqueItemsFunction checks uniqueItems, as subqueries are not allowed in CHECK constraints
+const arrayHasUniqueItemsFunction = `CREATE OR REPLACE FUNCTION array_has_unique_items(items anyarray) RETURNS boolean
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT cardinality(items) = (SELECT count(DISTINCT item) FROM unnest(items) AS item)
+$$;`
+
+// setOnUpdateFunction is run by the trigger keeping an onUpdate audit column, like updated_at, up to date
+const setOnUpdateFunction = `CREATE OR REPLACE FUNCTION set_%[1]s() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    NEW.%[1]s = NOW();
+    RETURN NEW;
+END;
+$$;`
+
 var datatypeMap = map[string]string{
 	"integer:":         "INTEGER",
 	"integer:int32":    "INTEGER",
@@ -61,6 +132,13 @@ var datatypeMap = map[string]string{
 	"string:binary":    "BYTEA",
 	"string:date":      "DATE",
 	"string:date-time": "TIMESTAMP",
+	"string:time":      "TIME",
+	"string:duration":  "INTERVAL",
+	"string:uuid":      "UUID",
+	"string:email":     "TEXT",
+	"string:uri":       "TEXT",
+	"string:ipv4":      "INET",
+	"string:ipv6":      "INET",
 	"string:enum":      "TEXT",
 	"array:":           "JSON",
 	"object:":          "JSON",
@@ -71,16 +149,41 @@ func (mm MinMaxConstraint) GetConstraint(columnName string) []string {
 	conditions := make([]string, 0, 2)
 
 	if mm.Minimum != nil {
-		conditions = append(conditions, fmt.Sprintf("%s >= %f", columnName, *mm.Minimum))
+		conditions = append(conditions, fmt.Sprintf("%s >= %s", columnName, formatNumber(*mm.Minimum)))
 	}
 
 	if mm.Maximum != nil {
-		conditions = append(conditions, fmt.Sprintf("%s <= %f", columnName, *mm.Maximum))
+		conditions = append(conditions, fmt.Sprintf("%s <= %s", columnName, formatNumber(*mm.Maximum)))
 	}
 
 	return conditions
 }
 
+func (em ExclusiveMinMaxConstraint) GetConstraint(columnName string) []string {
+	conditions := make([]string, 0, 2)
+
+	if em.ExclusiveMinimum != nil {
+		conditions = append(conditions, fmt.Sprintf("%s > %s", columnName, formatNumber(*em.ExclusiveMinimum)))
+	}
+
+	if em.ExclusiveMaximum != nil {
+		conditions = append(conditions, fmt.Sprintf("%s < %s", columnName, formatNumber(*em.ExclusiveMaximum)))
+	}
+
+	return conditions
+}
+
+func (vc ValuesConstraint) GetConstraint(columnName string) []string {
+	switch len(vc.Values) {
+	case 0:
+		return []string{}
+	case 1:
+		return []string{fmt.Sprintf("%s = %s", columnName, vc.Values[0])}
+	default:
+		return []string{fmt.Sprintf("%s IN (%s)", columnName, strings.Join(vc.Values, ", "))}
+	}
+}
+
 func (cl CharLengthConstraint) GetConstraint(columnName string) []string {
 	conditions := make([]string, 0, 2)
 
@@ -95,21 +198,64 @@ func (cl CharLengthConstraint) GetConstraint(columnName string) []string {
 	return conditions
 }
 
+func (ac ArrayConstraint) GetConstraint(columnName string) []string {
+	conditions := make([]string, 0, 3)
+
+	if ac.MinItems != nil {
+		conditions = append(conditions, fmt.Sprintf("cardinality(%s) >= %d", columnName, *ac.MinItems))
+	}
+
+	if ac.MaxItems != nil {
+		conditions = append(conditions, fmt.Sprintf("cardinality(%s) <= %d", columnName, *ac.MaxItems))
+	}
+
+	if ac.UniqueItems {
+		conditions = append(conditions, fmt.Sprintf("array_has_unique_items(%s)", columnName))
+	}
+
+	if len(ac.ItemValues) > 0 {
+		conditions = append(conditions, fmt.Sprintf("%s <@ ARRAY[%s]", columnName, strings.Join(ac.ItemValues, ", ")))
+	}
+
+	return conditions
+}
+
+func (fc FormatConstraint) GetConstraint(columnName string) []string {
+	if pattern, ok := formatPatterns[fc.Format]; ok {
+		return []string{fmt.Sprintf("%s ~ %s", columnName, quoteLiteral(pattern))}
+	}
+	return []string{}
+}
+
 func (pc PatternConstraint) GetConstraint(columnName string) []string {
 	if pc.Pattern != "" {
-		return []string{fmt.Sprintf("%s ~ '%s'", columnName, pc.Pattern)}
+		return []string{fmt.Sprintf("%s ~ %s", columnName, quoteLiteral(pc.Pattern))}
 	}
 	return []string{}
 }
 
-func (c Column) GetConstraint() string {
+func (cc CheckConstraint) GetConstraint(columnName string) []string {
+	if cc.Expression != "" {
+		return []string{cc.Expression}
+	}
+	return []string{}
+}
+
+func (c Column) checkConditions() []string {
 	conditions := make([]string, 0, 5) // Pre-allocate with expected capacity
 
-	constraints := []Constraint{c.MinMaxConstraint, c.CharLengthConstraint, c.PatternConstraint}
+	constraints := []Constraint{c.MinMaxConstraint, c.ExclusiveMinMaxConstraint, c.ValuesConstraint, c.CharLengthConstraint, c.PatternConstraint, c.ArrayConstraint, c.FormatConstraint, c.MultipleOfConstraint}
+	constraints = append(constraints, c.Constraints...)
 	for _, constraint := range constraints {
 		conditions = append(conditions, constraint.GetConstraint(c.Name)...)
 	}
 
+	return conditions
+}
+
+func (c Column) GetConstraint() string {
+	conditions := c.checkConditions()
+
 	if len(conditions) > 0 {
 		return " CHECK (" + strings.Join(conditions, " AND ") + ")"
 	}
@@ -122,31 +268,38 @@ func (c Column) CreateSQLStatement() (string, error) {
 	var pgDataType string
 	var ok bool
 
-	pgDataType, ok = datatypeMap[c.DataType+":"+c.DataFormat]
-	if !ok {
-		fmt.Printf("Unknown data type: %s\n", c.DataType)
-		return "", fmt.Errorf("unknown data type: %s", c.DataType)
+	// The type of columns built from a schema is resolved when building them
+	if c.SQLType != "" {
+		pgDataType = c.SQLType
+	} else {
+		pgDataType, ok = datatypeMap[c.DataType+":"+c.DataFormat]
+		if !ok {
+			fmt.Printf("Unknown data type: %s\n", c.DataType)
+			return "", fmt.Errorf("unknown data type: %s", c.DataType)
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("%s %s", c.Name, pgDataType))
+
+	if c.Storage != "" {
+		sb.WriteString(fmt.Sprintf(" STORAGE %s", c.Storage))
 	}
 
-	// Handle special case for id column
-	if c.Name == "id" && c.DataType == "integer" {
-		pgDataType = "BIGSERIAL"
-		c.NotNull = true
+	if c.Compression != "" {
+		sb.WriteString(fmt.Sprintf(" COMPRESSION %s", c.Compression))
 	}
 
-	// Handle special case for created_at and updated_at columns
-	if c.Name == "created_at" || c.Name == "updated_at" || c.Name == "deleted_at" {
-		pgDataType = "TIMESTAMP"
-		c.NotNull = true
-		c.DefaultValue = "NOW()"
+	if c.Collation != "" {
+		sb.WriteString(fmt.Sprintf(" COLLATE \"%s\"", strings.ReplaceAll(c.Collation, `"`, `""`)))
 	}
 
-	// Handle special case for enum
-	if c.DataType == "string" && len(c.Enum) > 0 && c.customType != "" {
-		pgDataType = c.customType
+	if c.Identity != "" {
+		sb.WriteString(fmt.Sprintf(" GENERATED %s AS IDENTITY", c.Identity))
 	}
 
-	sb.WriteString(fmt.Sprintf("%s %s", c.Name, pgDataType))
+	if c.Generated != "" {
+		sb.WriteString(fmt.Sprintf(" GENERATED ALWAYS AS (%s) STORED", c.Generated))
+	}
 
 	if c.NotNull {
 		sb.WriteString(" NOT NULL")
@@ -159,32 +312,47 @@ func (c Column) CreateSQLStatement() (string, error) {
 	// Handle constraints
 	sb.WriteString(c.GetConstraint())
 
+	// Defaults are SQL expressions, literals are rendered when building the column
 	if c.DefaultValue != "" {
-		if pgDataType == "TEXT" {
-			sb.WriteString(fmt.Sprintf(" DEFAULT '%s'", c.DefaultValue))
-		} else {
-			sb.WriteString(fmt.Sprintf(" DEFAULT %s", c.DefaultValue))
-		}
+		sb.WriteString(fmt.Sprintf(" DEFAULT %s", c.DefaultValue))
 	}
 
 	if c.Unique {
 		sb.WriteString(" UNIQUE")
 	}
 
-	if c.ForeignKey != "" {
-		sb.WriteString(fmt.Sprintf(" REFERENCES %s(id)", c.ForeignKey))
+	if c.ForeignKey.Name != "" {
+		sb.WriteString(fmt.Sprintf(" REFERENCES %s(id)", c.ForeignKey.sqlName()))
 	}
 
 	return sb.String(), nil
 }
 
-func buildColumnFromProperty(tableName string, property orderedmap.Pair[string, *highbase.SchemaProxy], requiredColumns []string) (Column, error) {
-	columnName := property.Key()
-	columnSchema := property.Value().Schema()
+func buildColumnFromProperty(tableName string, property orderedmap.Pair[string, *highbase.SchemaProxy], requiredColumns []string, options Options) (Column, error) {
+	schema, ref := property.Value().Schema(), property.Value().GetReference()
+	column, err := buildColumn(tableName, property.Key(), schema, ref, requiredColumns, options)
+	if err != nil {
+		return Column{}, err
+	}
 
+	// readOnly, writeOnly and the documentation are about the property, not about the entity it references
+	if ref == "" || !isDatabaseEntity(schema) {
+		column.ReadOnly = schema.ReadOnly != nil && *schema.ReadOnly
+		column.WriteOnly = schema.WriteOnly != nil && *schema.WriteOnly
+		column.Description = schemaComment(schema.Title, schema.Description)
+		column.Deprecated = schema.Deprecated != nil && *schema.Deprecated
+	}
+	return column, nil
+}
+
+// buildColumn builds the column of a property schema. ref is the $ref of the property, if any.
+func buildColumn(tableName string, columnName string, columnSchema *highbase.Schema, ref string, requiredColumns []string, options Options) (Column, error) {
 	var dataType string
 	if len(columnSchema.Type) > 0 {
 		dataType = columnSchema.Type[0]
+	} else if columnSchema.AdditionalProperties != nil || (ref != "" && isDatabaseEntity(columnSchema)) {
+		// A map or a referenced entity without explicit type
+		dataType = "object"
 	} else {
 		return Column{}, fmt.Errorf("no data type found for property: %s", columnName)
 	}
@@ -196,48 +364,251 @@ func buildColumnFromProperty(tableName string, property orderedmap.Pair[string,
 
 	// Detect if the property is a $ref to another schema
 	// This is used to determine if the column is a foreign key
-	ref := property.Value().GetReference()
-	var foreignKey string
+	var foreignKey TableReference
 
-	if ref != "" || (dataType == "array" && columnSchema.Items != nil && columnSchema.Items.A.Schema().Properties != nil) {
-		foreignKey = inflection.Plural(columnName)
-		columnName = inflection.Singular(columnName) + "_id"
-		dataType = "integer"
+	// A reference to a primitive component uses its domain
+	if ref != "" && isDomainSchema(columnSchema) {
+		notNull := (columnSchema.Nullable != nil && !*columnSchema.Nullable) || slices.Contains(requiredColumns, columnName)
+		return buildDomainColumn(options.naming().ColumnName(columnName), ref, columnSchema, notNull, options)
 	}
 
-	// Handle default value
-	defaultValue := ""
-	if columnSchema.Default != nil {
-		defaultValue = columnSchema.Default.Value
+	// Objects stored in a composite type, referenced or inline
+	if isCompositeSchema(columnSchema) {
+		notNull := (columnSchema.Nullable != nil && !*columnSchema.Nullable) || slices.Contains(requiredColumns, columnName)
+		return buildCompositeColumn(tableName, columnName, ref, columnSchema, notNull, options)
+	}
+
+	// Only references to entities are foreign keys, other references are stored like their schema
+	var referencedSchema *highbase.Schema
+	if ref != "" && isDatabaseEntity(columnSchema) {
+		foreignKey, referencedSchema = referencedEntity(ref, columnSchema, options)
+	} else if dataType == "array" && columnSchema.Items != nil && columnSchema.Items.IsA() {
+		itemRef := columnSchema.Items.A.GetReference()
+		itemSchema := columnSchema.Items.A.Schema()
+		if itemRef != "" && isDatabaseEntity(itemSchema) {
+			foreignKey, referencedSchema = referencedEntity(itemRef, itemSchema, options)
+		} else if itemRef == "" && itemSchema != nil && itemSchema.Properties != nil {
+			foreignKey = TableReference{Name: options.naming().TableName(columnName)}
+			referencedSchema = itemSchema
+		}
+	}
+
+	// A foreign key has the type of the id it references
+	propertyName := columnName
+	var foreignKeyType string
+	if foreignKey.Name != "" {
+		columnName = options.naming().Singular(columnName) + "_id"
+
+		idColumn, ok, err := entityIdColumn(foreignKey.Name, referencedSchema, options)
+		if err != nil {
+			return Column{}, fmt.Errorf("%s: %v", columnName, err)
+		}
+		if !ok {
+			return Column{}, fmt.Errorf("%s references the table %s, which has no id", propertyName, foreignKey)
+		}
+		referencingColumn := referencingIdColumn(idColumn, columnName, foreignKey)
+		dataType, dataFormat, foreignKeyType = referencingColumn.DataType, referencingColumn.DataFormat, referencingColumn.SQLType
+	}
+
+	// Resolve the PostgreSQL type. Unknown types are reported when creating the SQL statement.
+	sqlType, _ := options.sqlType(dataType, dataFormat)
+	var prerequisites []string
+
+	// Explicit types, spatial objects and ranges are stored in a single column of their type
+	explicitType, hasExplicitType := extensionValue(columnSchema, "x-sql-type")
+	switch {
+	case foreignKey.Name != "":
+		if foreignKeyType != "" {
+			sqlType = foreignKeyType
+		}
+	case hasExplicitType:
+		if err := validateTypeName(explicitType); err != nil {
+			return Column{}, fmt.Errorf("invalid x-sql-type for %s: %v", columnName, err)
+		}
+		sqlType = explicitType
+	case isGeoJSONSchema(columnSchema):
+		sqlType = geometrySQLType(columnSchema)
+	case isRangeSchema(columnSchema):
+		rangeType, err := rangeSQLType(columnSchema, options)
+		if err != nil {
+			return Column{}, fmt.Errorf("%s: %v", columnName, err)
+		}
+		sqlType = rangeType
+	}
+
+	// Exact numbers get a precision and a scale. multipleOf is checked when the type does not enforce it.
+	var multipleOfConstraint MultipleOfConstraint
+	switch {
+	case dataType == "number" && strings.EqualFold(sqlType, "NUMERIC"):
+		precision, scale, ok, err := numericTypeModifiers(columnSchema)
+		if err != nil {
+			return Column{}, fmt.Errorf("%s: %v", columnName, err)
+		}
+		if ok {
+			sqlType = fmt.Sprintf("NUMERIC(%d,%d)", precision, scale)
+		}
+		if columnSchema.MultipleOf != nil && !(ok && enforcedByScale(*columnSchema.MultipleOf, scale)) {
+			multipleOfConstraint.MultipleOf = columnSchema.MultipleOf
+		}
+	case dataType == "integer" && foreignKey.Name == "" && columnSchema.MultipleOf != nil && *columnSchema.MultipleOf != 1:
+		multipleOfConstraint.MultipleOf = columnSchema.MultipleOf
+	}
+
+	// Arrays of primitive types are native PostgreSQL arrays
+	var arrayConstraint ArrayConstraint
+	var nativeArray bool
+	var enum []string
+	var enumType string
+	if dataType == "array" && !hasExplicitType && columnSchema.Items != nil && columnSchema.Items.IsA() {
+		itemSchema := columnSchema.Items.A.Schema()
+		if itemSchema != nil && len(itemSchema.Type) > 0 && slices.Contains(arrayItemTypes, itemSchema.Type[0]) {
+			itemType, ok := options.sqlType(itemSchema.Type[0], itemSchema.Format)
+			if !ok {
+				return Column{}, fmt.Errorf("unknown data type for the items of %s: %s", columnName, itemSchema.Type[0])
+			}
+			arrayConstraint = ArrayConstraint{
+				MinItems:    columnSchema.MinItems,
+				MaxItems:    columnSchema.MaxItems,
+				UniqueItems: columnSchema.UniqueItems != nil && *columnSchema.UniqueItems,
+			}
+
+			// String enum items are stored as their enum type, or checked against the enum values
+			if isStringEnum(itemSchema) {
+				strategy, err := enumStrategy(itemSchema, options)
+				if err != nil {
+					return Column{}, err
+				}
+
+				var values []string
+				for _, item := range itemSchema.Enum {
+					values = append(values, item.Value)
+				}
+
+				itemRef := columnSchema.Items.A.GetReference()
+				typeName := enumTypeName(tableName, columnName, itemRef, options)
+				switch {
+				case strategy == EnumStrategyCheck || strategy == EnumStrategyTable:
+					// Array items cannot reference a lookup table, they are checked against the values
+					for _, value := range values {
+						arrayConstraint.ItemValues = append(arrayConstraint.ItemValues, quoteLiteral(value))
+					}
+				case itemRef != "":
+					// Shared enum types are created once, before the tables
+					enumSQL, err := GenerateEnumSQL(typeName, values)
+					if err != nil {
+						return Column{}, err
+					}
+					itemType = typeName
+					prerequisites = append(prerequisites, enumSQL)
+				default:
+					enum = values
+					enumType = typeName
+					itemType = typeName
+				}
+			}
+
+			sqlType = itemType + "[]"
+			nativeArray = true
+			if arrayConstraint.UniqueItems {
+				prerequisites = append(prerequisites, arrayHasUniqueItemsFunction)
+			}
+		}
+	}
+
+	if extension := createExtensionStatement(sqlType); extension != "" {
+		prerequisites = append(prerequisites, extension)
 	}
 
 	// Handle possible values for the column (Constraints)
+	// On native arrays, uniqueItems is about the items of each value (see ArrayConstraint)
 	var unique bool
-	if columnSchema.UniqueItems != nil && *columnSchema.UniqueItems {
+	if columnSchema.UniqueItems != nil && *columnSchema.UniqueItems && !nativeArray {
 		unique = true
 	}
 
-	// Handle enum values
-	var enum []string
-	var enumType string
-	if columnSchema.Enum != nil {
+	// Handle enum values. String enums are stored according to their strategy, other enums and const are checked.
+	var valuesConstraint ValuesConstraint
+	if columnSchema.Enum != nil && dataType == "string" {
+		strategy, err := enumStrategy(columnSchema, options)
+		if err != nil {
+			return Column{}, err
+		}
+
+		var values []string
 		for _, item := range columnSchema.Enum {
-			enum = append(enum, item.Value)
+			values = append(values, item.Value)
 		}
-		enumType = tableName + "_" + columnName
-	}
-
-	return Column{
-		Name:         columnName,
-		DataType:     dataType,
-		DataFormat:   dataFormat,
-		PrimaryKey:   columnName == "id",
-		NotNull:      (columnSchema.Nullable != nil && !*columnSchema.Nullable) || slices.Contains(requiredColumns, columnName),
-		DefaultValue: defaultValue,
-		MinMaxConstraint: MinMaxConstraint{
-			Minimum: columnSchema.Minimum,
-			Maximum: columnSchema.Maximum,
-		},
+
+		typeName := enumTypeName(tableName, columnName, ref, options)
+		switch {
+		case strategy == EnumStrategyCheck:
+			for _, value := range values {
+				valuesConstraint.Values = append(valuesConstraint.Values, quoteLiteral(value))
+			}
+		case strategy == EnumStrategyTable:
+			foreignKey = TableReference{Name: options.naming().TableName(typeName)}
+			prerequisites = append(prerequisites, lookupTableStatements(foreignKey.Name, values))
+		case ref != "":
+			// Shared enum types are created once, before the tables
+			enumSQL, err := GenerateEnumSQL(typeName, values)
+			if err != nil {
+				return Column{}, err
+			}
+			sqlType = typeName
+			prerequisites = append(prerequisites, enumSQL)
+		default:
+			enum = values
+			enumType = typeName
+			sqlType = typeName
+		}
+	} else if columnSchema.Enum != nil {
+		for _, item := range columnSchema.Enum {
+			value, err := sqlLiteral(dataType, item.Value)
+			if err != nil {
+				return Column{}, fmt.Errorf("invalid enum value for %s: %v", columnName, err)
+			}
+			valuesConstraint.Values = append(valuesConstraint.Values, value)
+		}
+	}
+	if columnSchema.Const != nil {
+		value, err := sqlLiteral(dataType, columnSchema.Const.Value)
+		if err != nil {
+			return Column{}, fmt.Errorf("invalid const for %s: %v", columnName, err)
+		}
+		valuesConstraint.Values = []string{value}
+	}
+
+	// Exclusive bounds are booleans modifying minimum / maximum in OpenAPI 3.0, and numbers in 3.1
+	minMaxConstraint := MinMaxConstraint{
+		Minimum: columnSchema.Minimum,
+		Maximum: columnSchema.Maximum,
+	}
+	var exclusiveMinMaxConstraint ExclusiveMinMaxConstraint
+	if bound := columnSchema.ExclusiveMinimum; bound != nil {
+		if bound.IsA() && bound.A {
+			exclusiveMinMaxConstraint.ExclusiveMinimum, minMaxConstraint.Minimum = minMaxConstraint.Minimum, nil
+		} else if bound.IsB() {
+			exclusiveMinMaxConstraint.ExclusiveMinimum = &bound.B
+		}
+	}
+	if bound := columnSchema.ExclusiveMaximum; bound != nil {
+		if bound.IsA() && bound.A {
+			exclusiveMinMaxConstraint.ExclusiveMaximum, minMaxConstraint.Maximum = minMaxConstraint.Maximum, nil
+		} else if bound.IsB() {
+			exclusiveMinMaxConstraint.ExclusiveMaximum = &bound.B
+		}
+	}
+
+	column := Column{
+		Name:                      options.naming().ColumnName(columnName),
+		DataType:                  dataType,
+		DataFormat:                dataFormat,
+		SQLType:                   sqlType,
+		PrimaryKey:                columnName == "id",
+		NotNull:                   (columnSchema.Nullable != nil && !*columnSchema.Nullable) || slices.Contains(requiredColumns, propertyName),
+		MinMaxConstraint:          minMaxConstraint,
+		ExclusiveMinMaxConstraint: exclusiveMinMaxConstraint,
+		ValuesConstraint:          valuesConstraint,
 		CharLengthConstraint: CharLengthConstraint{
 			MinLength: columnSchema.MinLength,
 			MaxLength: columnSchema.MaxLength,
@@ -245,23 +616,99 @@ func buildColumnFromProperty(tableName string, property orderedmap.Pair[string,
 		PatternConstraint: PatternConstraint{
 			Pattern: columnSchema.Pattern,
 		},
-		Unique:     unique,
-		customType: enumType,
-		Enum:       enum,
-		ForeignKey: foreignKey,
-	}, nil
+		ArrayConstraint: arrayConstraint,
+		FormatConstraint: FormatConstraint{
+			Format: dataFormat,
+		},
+		MultipleOfConstraint: multipleOfConstraint,
+		Unique:               unique,
+		customType:           enumType,
+		Enum:                 enum,
+		ForeignKey:           foreignKey,
+		prerequisites:        prerequisites,
+	}
+
+	// Handle default value
+	defaultValue, err := defaultExpression(column, columnSchema)
+	if err != nil {
+		return Column{}, err
+	}
+	column.DefaultValue = defaultValue
+
+	// Generate the values of ids
+	strategy, err := idStrategy(columnSchema, column, options)
+	if err != nil {
+		return Column{}, err
+	}
+	column.applyIDStrategy(strategy)
+
+	// Handle audit columns like created_at and updated_at
+	if auditColumn, ok := options.auditColumn(column.Name); ok {
+		column.SQLType = auditColumn.Type
+		column.NotNull = auditColumn.NotNull
+		column.DefaultValue = auditColumn.Default
+	}
+
+	// Timestamp audit columns like updated_at are set on every update by a trigger
+	if auditColumn, ok := options.auditColumn(column.Name); ok && auditColumn.OnUpdate && strings.HasPrefix(strings.ToUpper(column.SQLType), "TIMESTAMP") {
+		column.setOnUpdate = true
+		column.prerequisites = append(column.prerequisites, fmt.Sprintf(setOnUpdateFunction, column.Name))
+	}
+
+	// JSONB columns tagged x-searchable get a GIN index
+	if val, ok := extensionValue(columnSchema, "x-searchable"); ok && val == "true" {
+		column.GinIndex = true
+	}
+
+	// Raw SQL overrides of the column, checked by the PostgreSQL parser. The extensions of a referenced
+	// entity are not about the foreign key column.
+	if foreignKey.Name == "" && hasOverrides(columnSchema) {
+		if err := column.applyOverrides(columnSchema); err != nil {
+			return Column{}, err
+		}
+		if err := validateColumnDefinition(column); err != nil {
+			return Column{}, fmt.Errorf("%s: %v", columnName, err)
+		}
+	}
+
+	return column, nil
 }
 
-func BuildColumnsFromSchema(tableName string, properties orderedmap.Map[string, *highbase.SchemaProxy], requiredColumns []string) ([]Column, error) {
+func BuildColumnsFromSchema(tableName string, properties orderedmap.Map[string, *highbase.SchemaProxy], requiredColumns []string, options Options) ([]Column, error) {
 
 	var columns []Column
 
 	for property := properties.First(); property != nil; property = property.Next() {
-		column, err := buildColumnFromProperty(tableName, property, requiredColumns)
+		if !isDatabaseColumn(property.Value()) {
+			continue
+		}
+
+		storage, err := nestedObjectStorage(property.Value())
 		if err != nil {
-			slog.Error("error building column for %s: %v", property.Key(), err)
-			return nil, fmt.Errorf("could not build column for %s", property.Key())
+			return nil, fmt.Errorf("could not build column for %s: %v", property.Key(), err)
+		}
 
+		switch storage {
+		case StorageTable:
+			// Stored in a child table, see buildNestedTables
+			continue
+		case StorageFlatten:
+			flattenedColumns, err := buildFlattenedColumns(tableName, property, requiredColumns, options)
+			if err != nil {
+				return nil, fmt.Errorf("could not build columns for %s: %v", property.Key(), err)
+			}
+			columns = append(columns, flattenedColumns...)
+			continue
+		}
+
+		var column Column
+		if storage == StorageJSONB {
+			column, err = buildJSONBColumn(tableName, property, requiredColumns, options)
+		} else {
+			column, err = buildColumnFromProperty(tableName, property, requiredColumns, options)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not build column for %s: %w", property.Key(), err)
 		}
 		columns = append(columns, column)
 	}
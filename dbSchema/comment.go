@@ -0,0 +1,57 @@
+package dbSchema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// schemaComment returns the comment documenting a schema: its title and its description
+func schemaComment(title string, description string) string {
+	var parts []string
+	for _, part := range []string{title, description} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ": ")
+}
+
+// comment returns the comment of a column: its description, whether it is deprecated and how it is
+// accessed when it is readOnly or writeOnly
+func (c Column) comment() string {
+	var parts []string
+
+	description := c.Description
+	if c.Deprecated {
+		description = strings.TrimSuffix("DEPRECATED: "+description, ": ")
+	}
+	if description != "" {
+		parts = append(parts, description)
+	}
+
+	switch {
+	case c.WriteOnly:
+		parts = append(parts, "writeOnly: store a hash, excluded from SELECT queries")
+	case c.ReadOnly:
+		parts = append(parts, "readOnly: set by the database, excluded from INSERT parameters")
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// commentStatements documents the table and its columns with COMMENT ON statements
+func (t Table) commentStatements() string {
+	var sb strings.Builder
+
+	if t.Comment != "" {
+		sb.WriteString(fmt.Sprintf("\n\nCOMMENT ON TABLE %s IS %s;", t.sqlName(), quoteLiteral(t.Comment)))
+	}
+
+	for _, column := range t.ColumnDefinition {
+		if comment := column.comment(); comment != "" {
+			sb.WriteString(fmt.Sprintf("\n\nCOMMENT ON COLUMN %s.%s IS %s;", t.sqlName(), quoteIdentifier(column.Name), quoteLiteral(comment)))
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,93 @@
+package dbSchema
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// Built-in PostgreSQL types a composite type cannot be named after, as they would shadow it
+var builtinTypeNames = []string{
+	"bigint", "bool", "boolean", "box", "bytea", "char", "cidr", "circle", "date", "decimal", "float4", "float8",
+	"inet", "int", "int2", "int4", "int8", "integer", "interval", "json", "jsonb", "line", "lseg", "macaddr",
+	"money", "numeric", "path", "point", "polygon", "real", "smallint", "text", "time", "timestamp",
+	"timestamptz", "timetz", "tsquery", "tsvector", "uuid", "varchar", "xml",
+}
+
+// isCompositeSchema tells if an object schema is stored in a composite type (x-storage: composite)
+func isCompositeSchema(schema *highbase.Schema) bool {
+	if schema == nil || schema.Properties == nil {
+		return false
+	}
+	storage, _ := extensionValue(schema, "x-storage")
+	return storage == StorageComposite
+}
+
+// compositeTypeName returns the name of the composite type of an object: x-type-name, the name of the
+// referenced component, or <table>_<property> for an inline object
+func compositeTypeName(tableName string, columnName string, ref string, schema *highbase.Schema, options Options) (string, error) {
+	typeName, ok := extensionValue(schema, "x-type-name")
+	if !ok {
+		typeName = enumTypeName(tableName, columnName, ref, options)
+	}
+
+	if slices.Contains(builtinTypeNames, strings.ToLower(typeName)) {
+		return "", fmt.Errorf("the composite type %s would shadow a built-in type, name it with x-type-name", typeName)
+	}
+	return typeName, nil
+}
+
+// createCompositeTypeStatement returns the CREATE TYPE of an object schema, and the statements the type depends on.
+// Attributes of a composite type have no constraints.
+func createCompositeTypeStatement(typeName string, schema *highbase.Schema, options Options) ([]string, error) {
+	var prerequisites []string
+	var attributes []string
+
+	for property := schema.Properties.First(); property != nil; property = property.Next() {
+		if !isDatabaseColumn(property.Value()) {
+			continue
+		}
+
+		attribute, err := buildColumnFromProperty(typeName, property, nil, options)
+		if err != nil {
+			return nil, fmt.Errorf("composite type %s: %v", typeName, err)
+		}
+
+		prerequisites = append(prerequisites, attribute.prerequisites...)
+		if len(attribute.Enum) > 0 {
+			enumSQL, err := GenerateEnumSQL(attribute.customType, attribute.Enum)
+			if err != nil {
+				return nil, err
+			}
+			prerequisites = append(prerequisites, enumSQL)
+		}
+
+		attributes = append(attributes, fmt.Sprintf("%s %s", quoteIdentifier(attribute.Name), attribute.SQLType))
+	}
+
+	statement := fmt.Sprintf("CREATE TYPE %s AS (\n%s\n);", quoteIdentifier(typeName), strings.Join(attributes, ",\n"))
+	return append(prerequisites, statement), nil
+}
+
+// buildCompositeColumn builds a column storing an object in its composite type
+func buildCompositeColumn(tableName string, columnName string, ref string, schema *highbase.Schema, notNull bool, options Options) (Column, error) {
+	typeName, err := compositeTypeName(tableName, columnName, ref, schema, options)
+	if err != nil {
+		return Column{}, err
+	}
+
+	prerequisites, err := createCompositeTypeStatement(typeName, schema, options)
+	if err != nil {
+		return Column{}, err
+	}
+
+	return Column{
+		Name:          options.naming().ColumnName(columnName),
+		DataType:      "object",
+		SQLType:       typeName,
+		NotNull:       notNull,
+		prerequisites: prerequisites,
+	}, nil
+}
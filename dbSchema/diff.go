@@ -0,0 +1,303 @@
+package dbSchema
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Difference describes a single structural mismatch between an expected and an actual schema
+type Difference struct {
+	Table    string
+	Column   string // Empty when the difference is about the whole table
+	Property string // Empty when the table or column is missing or unexpected
+	Expected string
+	Actual   string
+}
+
+func (d Difference) String() string {
+	var subject string
+	if d.Column != "" {
+		subject = fmt.Sprintf("column %s", d.Column)
+	} else {
+		subject = fmt.Sprintf("table %s", d.Table)
+	}
+
+	switch {
+	case d.Property != "":
+		return fmt.Sprintf("%s: %s differs (expected %s, found %s)", subject, d.Property, displayValue(d.Expected), displayValue(d.Actual))
+	case d.Actual == "":
+		return fmt.Sprintf("%s: missing", subject)
+	default:
+		return fmt.Sprintf("%s: unexpected", subject)
+	}
+}
+
+func displayValue(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+// DiffTables compares two table lists and returns the structural differences, table by table
+// and column by column. Tables and columns are matched by name.
+func DiffTables(expected, actual []Table) []Difference {
+	var differences []Difference
+
+	for _, expectedTable := range expected {
+		actualTable := findTable(actual, expectedTable.qualifiedName())
+		if actualTable == nil {
+			differences = append(differences, Difference{Table: expectedTable.qualifiedName(), Expected: expectedTable.qualifiedName()})
+			continue
+		}
+		differences = append(differences, diffColumns(expectedTable, *actualTable)...)
+	}
+
+	for _, actualTable := range actual {
+		if findTable(expected, actualTable.qualifiedName()) == nil {
+			differences = append(differences, Difference{Table: actualTable.qualifiedName(), Actual: actualTable.qualifiedName()})
+		}
+	}
+
+	return differences
+}
+
+func diffColumns(expected, actual Table) []Difference {
+	var differences []Difference
+
+	for _, expectedColumn := range expected.ColumnDefinition {
+		actualColumn := actual.column(expectedColumn.Name)
+		if actualColumn == nil {
+			differences = append(differences, Difference{Table: expected.qualifiedName(), Column: expectedColumn.Name, Expected: expectedColumn.Name})
+			continue
+		}
+
+		for _, property := range columnProperties {
+			expectedValue, actualValue := property.value(expectedColumn), property.value(*actualColumn)
+			if expectedValue != actualValue {
+				differences = append(differences, Difference{
+					Table:    expected.qualifiedName(),
+					Column:   expectedColumn.Name,
+					Property: property.name,
+					Expected: expectedValue,
+					Actual:   actualValue,
+				})
+			}
+		}
+	}
+
+	for _, actualColumn := range actual.ColumnDefinition {
+		if expected.column(actualColumn.Name) == nil {
+			differences = append(differences, Difference{Table: expected.qualifiedName(), Column: actualColumn.Name, Actual: actualColumn.Name})
+		}
+	}
+
+	for _, check := range expected.CheckConstraints {
+		if !slices.Contains(actual.CheckConstraints, check) {
+			differences = append(differences, Difference{Table: expected.qualifiedName(), Property: "check", Expected: check})
+		}
+	}
+
+	for _, check := range actual.CheckConstraints {
+		if !slices.Contains(expected.CheckConstraints, check) {
+			differences = append(differences, Difference{Table: expected.qualifiedName(), Property: "check", Actual: check})
+		}
+	}
+
+	return differences
+}
+
+// Column properties compared by DiffTables, in report order
+var columnProperties = []struct {
+	name  string
+	value func(Column) string
+}{
+	{"type", func(c Column) string { return c.SQLType }},
+	{"not null", func(c Column) string { return fmt.Sprint(c.NotNull) }},
+	{"primary key", func(c Column) string { return fmt.Sprint(c.PrimaryKey) }},
+	{"unique", func(c Column) string { return fmt.Sprint(c.Unique) }},
+	{"default", func(c Column) string { return c.DefaultValue }},
+	{"identity", func(c Column) string { return c.Identity }},
+	{"generated", func(c Column) string { return c.Generated }},
+	{"references", func(c Column) string { return c.ForeignKey.String() }},
+	{"check", func(c Column) string { return strings.Join(c.checkConditions(), " AND ") }},
+}
+
+// checkConstraintNames returns the names of the check constraints of a column read back from SQL,
+// or the default name when they are unnamed
+func (c Column) checkConstraintNames(defaultName string) []string {
+	var names []string
+	for _, constraint := range c.Constraints {
+		if check, ok := constraint.(CheckConstraint); ok && check.Name != "" && !slices.Contains(names, quoteIdentifier(check.Name)) {
+			names = append(names, quoteIdentifier(check.Name))
+		}
+	}
+	if len(names) == 0 {
+		return []string{defaultName}
+	}
+	return names
+}
+
+func findTable(tables []Table, name string) *Table {
+	for i := range tables {
+		if tables[i].qualifiedName() == name {
+			return &tables[i]
+		}
+	}
+	return nil
+}
+
+// ReconcileSQLStatements returns the statements that turn the actual schema into the expected one.
+// Missing tables are created first so that new foreign keys can reference them.
+// Dropped constraints keep the name read from the actual schema, new constraints are named after
+// PostgreSQL defaults (<table>_pkey, <table>_<column>_key, ...).
+func ReconcileSQLStatements(expected, actual []Table) ([]string, error) {
+	var createStatements, alterStatements []string
+
+	for _, difference := range DiffTables(expected, actual) {
+		statement, err := difference.reconcileSQLStatement(expected, actual)
+		if err != nil {
+			return nil, err
+		}
+
+		if difference.Column == "" && difference.Property == "" && difference.Actual == "" {
+			createStatements = append(createStatements, statement)
+		} else {
+			alterStatements = append(alterStatements, statement)
+		}
+	}
+
+	return append(createStatements, alterStatements...), nil
+}
+
+func (d Difference) reconcileSQLStatement(expected, actual []Table) (string, error) {
+	// Unexpected tables are only found in the actual schema
+	differingTable := findTable(expected, d.Table)
+	if differingTable == nil {
+		differingTable = findTable(actual, d.Table)
+	}
+	table := differingTable.sqlName()
+	column := quoteIdentifier(d.Column)
+
+	// Missing or unexpected table
+	if d.Column == "" && d.Property == "" {
+		if d.Actual != "" {
+			return fmt.Sprintf("DROP TABLE %s;", table), nil
+		}
+		statement, err := findTable(expected, d.Table).CreateSQLStatement()
+		return strings.TrimSpace(statement), err
+	}
+
+	// Missing or unexpected column
+	if d.Property == "" {
+		if d.Actual != "" {
+			return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", table, column), nil
+		}
+		definition, err := findTable(expected, d.Table).column(d.Column).CreateSQLStatement()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", table, definition), nil
+	}
+
+	relation := differingTable.Name
+	constraintName := func(suffix string) string {
+		return fmt.Sprintf("%s_%s_%s", relation, d.Column, suffix)
+	}
+
+	// The constraints to drop are named in the actual schema, or have their default name
+	actualTable := findTable(actual, d.Table)
+	var actualColumn *Column
+	if actualTable != nil {
+		actualColumn = actualTable.column(d.Column)
+	}
+	actualConstraintName := func(property string, defaultName string) string {
+		if actualColumn != nil && actualColumn.constraintNames[property] != "" {
+			return quoteIdentifier(actualColumn.constraintNames[property])
+		}
+		return defaultName
+	}
+
+	var statements []string
+
+	switch d.Property {
+	case "type":
+		sqlType := d.Expected
+		if integerType, ok := serialTypes[sqlType]; ok {
+			sqlType = integerType
+		}
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s;", table, column, sqlType, column, sqlType))
+	case "not null":
+		if d.Expected == "true" {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL;", table, column))
+		} else {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP NOT NULL;", table, column))
+		}
+	case "primary key":
+		if d.Expected == "true" {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (%s);", table, column))
+		} else {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", table, actualConstraintName(d.Property, relation+"_pkey")))
+		}
+	case "unique":
+		if d.Expected == "true" {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s UNIQUE (%s);", table, constraintName("key"), column))
+		} else {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", table, actualConstraintName(d.Property, constraintName("key"))))
+		}
+	case "default":
+		if d.Expected != "" {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s;", table, column, d.Expected))
+		} else {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT;", table, column))
+		}
+	case "identity":
+		if d.Expected == "" {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP IDENTITY;", table, column))
+		} else if d.Actual == "" {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s ADD GENERATED %s AS IDENTITY;", table, column, d.Expected))
+		} else {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET GENERATED %s;", table, column, d.Expected))
+		}
+	case "references":
+		if d.Actual != "" {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", table, actualConstraintName(d.Property, constraintName("fkey"))))
+		}
+		if d.Expected != "" {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s(id);", table, constraintName("fkey"), column, differingTable.column(d.Column).ForeignKey.sqlName()))
+		}
+	case "generated":
+		if d.Expected == "" {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP EXPRESSION;", table, column))
+		} else {
+			// A column cannot become generated or change its expression, it is created again
+			definition, err := differingTable.column(d.Column).CreateSQLStatement()
+			if err != nil {
+				return "", err
+			}
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", table, column))
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", table, definition))
+		}
+	case "check":
+		if d.Column != "" && d.Actual != "" {
+			for _, name := range actualColumn.checkConstraintNames(constraintName("check")) {
+				statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", table, name))
+			}
+		} else if d.Actual != "" && actualTable.checkNames[d.Actual] != "" {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", table, quoteIdentifier(actualTable.checkNames[d.Actual])))
+		} else if d.Actual != "" {
+			// Unnamed table check constraints have no predictable name
+			statements = append(statements, fmt.Sprintf("-- Drop the constraint CHECK (%s) on %s", d.Actual, table))
+		}
+		if d.Expected != "" {
+			if d.Column != "" {
+				statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s CHECK (%s);", table, constraintName("check"), d.Expected))
+			} else {
+				statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD CHECK (%s);", table, d.Expected))
+			}
+		}
+	}
+
+	return strings.Join(statements, "\n"), nil
+}
@@ -0,0 +1,59 @@
+package dbSchema
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// isDomainSchema tells if a component schema is a primitive, like Email or PositiveAmount, stored as a domain
+func isDomainSchema(schema *highbase.Schema) bool {
+	return schema != nil && len(schema.Type) > 0 && slices.Contains(arrayItemTypes, schema.Type[0]) &&
+		schema.Properties == nil && !isStringEnum(schema)
+}
+
+// createDomainStatement returns the CREATE DOMAIN of a primitive component schema, with its CHECKs and
+// default, and the statements the domain depends on
+func createDomainStatement(domainName string, schema *highbase.Schema, options Options) ([]string, error) {
+	if slices.Contains(builtinTypeNames, strings.ToLower(domainName)) {
+		return nil, fmt.Errorf("the domain %s would shadow a built-in type, name it with x-type-name", domainName)
+	}
+
+	value, err := buildColumn(domainName, "VALUE", schema, "", nil, options)
+	if err != nil {
+		return nil, fmt.Errorf("domain %s: %v", domainName, err)
+	}
+
+	statement := fmt.Sprintf("CREATE DOMAIN %s AS %s", quoteIdentifier(domainName), value.SQLType)
+	if value.DefaultValue != "" {
+		statement += fmt.Sprintf(" DEFAULT %s", value.DefaultValue)
+	}
+	statement += value.GetConstraint() + ";"
+
+	return append(value.prerequisites, statement), nil
+}
+
+// buildDomainColumn builds a column whose property references a primitive component schema. Its type is
+// the domain of the component, named with x-type-name or after the component, which holds the constraints.
+func buildDomainColumn(columnName string, ref string, schema *highbase.Schema, notNull bool, options Options) (Column, error) {
+	domainName, ok := extensionValue(schema, "x-type-name")
+	if !ok {
+		domainName = options.naming().TypeName(schemaNameFromReference(ref))
+	}
+
+	prerequisites, err := createDomainStatement(domainName, schema, options)
+	if err != nil {
+		return Column{}, err
+	}
+
+	return Column{
+		Name:          columnName,
+		DataType:      schema.Type[0],
+		DataFormat:    schema.Format,
+		SQLType:       domainName,
+		NotNull:       notNull,
+		prerequisites: prerequisites,
+	}, nil
+}
@@ -0,0 +1,58 @@
+package dbSchema
+
+import (
+	"fmt"
+	"strings"
+
+	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// Storages of a string enum, chosen with the enumStrategy option or the x-enum-strategy extension
+const (
+	EnumStrategyType  = "type"  // CREATE TYPE ... AS ENUM (default)
+	EnumStrategyCheck = "check" // TEXT column with a CHECK on the values
+	EnumStrategyTable = "table" // Lookup table seeded with the values, referenced by a foreign key
+)
+
+// isStringEnum tells if a schema is a string enum, e.g. a shared enum component
+func isStringEnum(schema *highbase.Schema) bool {
+	return schema != nil && len(schema.Enum) > 0 && len(schema.Type) > 0 && schema.Type[0] == "string"
+}
+
+// enumStrategy returns the storage of a string enum
+func enumStrategy(schema *highbase.Schema, options Options) (string, error) {
+	strategy, ok := extensionValue(schema, "x-enum-strategy")
+	if !ok {
+		strategy = options.EnumStrategy
+	}
+
+	switch strategy {
+	case "":
+		return EnumStrategyType, nil
+	case EnumStrategyType, EnumStrategyCheck, EnumStrategyTable:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown x-enum-strategy: %s", strategy)
+	}
+}
+
+// enumTypeName returns the name of the type of an enum column. An enum shared through a $ref is
+// named after its component, so that its type or lookup table is created once.
+func enumTypeName(tableName string, columnName string, ref string, options Options) string {
+	naming := options.naming()
+	if ref != "" {
+		return naming.TypeName(schemaNameFromReference(ref))
+	}
+	return naming.Singular(naming.TableName(tableName)) + "_" + naming.ColumnName(columnName)
+}
+
+// lookupTableStatements creates the lookup table of an enum and seeds it with the enum values
+func lookupTableStatements(lookupTable string, values []string) string {
+	var rows []string
+	for _, value := range values {
+		rows = append(rows, fmt.Sprintf("(%s)", quoteLiteral(value)))
+	}
+
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\nid TEXT NOT NULL PRIMARY KEY\n);\n\nINSERT INTO %s (id) VALUES %s ON CONFLICT DO NOTHING;",
+		quoteIdentifier(lookupTable), quoteIdentifier(lookupTable), strings.Join(rows, ", "))
+}
@@ -0,0 +1,37 @@
+package dbSchema
+
+import (
+	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// extensionValue returns the scalar value of a custom x- extension of a schema
+func extensionValue(schema *highbase.Schema, name string) (string, bool) {
+	if schema == nil || schema.Extensions == nil {
+		return "", false
+	}
+
+	val, ok := schema.Extensions.Get(name)
+	if !ok || val == nil {
+		return "", false
+	}
+
+	return val.Value, true
+}
+
+// extensionValues returns the values of a custom x- extension of a schema holding a list
+func extensionValues(schema *highbase.Schema, name string) ([]string, bool) {
+	if schema == nil || schema.Extensions == nil {
+		return nil, false
+	}
+
+	val, ok := schema.Extensions.Get(name)
+	if !ok || val == nil {
+		return nil, false
+	}
+
+	var values []string
+	for _, item := range val.Content {
+		values = append(values, item.Value)
+	}
+	return values, true
+}
@@ -0,0 +1,63 @@
+package dbSchema
+
+import (
+	"fmt"
+	"strings"
+
+	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// Column of the tsvector generated from the x-fulltext properties of a schema
+const searchVectorColumn = "search_vector"
+
+// Text search configuration used when x-fulltext-language is not set
+const defaultFullTextLanguage = "simple"
+
+// addFullTextSearch adds the search_vector column generated from the string properties listed in the
+// x-fulltext extension of a schema, indexed with a GIN index
+func (t *Table) addFullTextSearch(schema *highbase.Schema, options Options) error {
+	properties, ok := extensionValues(schema, "x-fulltext")
+	if !ok {
+		return nil
+	}
+	if len(properties) == 0 {
+		return fmt.Errorf("x-fulltext of %s must list properties", t.Name)
+	}
+
+	language, ok := extensionValue(schema, "x-fulltext-language")
+	if !ok {
+		language = defaultFullTextLanguage
+	}
+
+	var documents []string
+	for _, property := range properties {
+		column := t.column(options.naming().ColumnName(property))
+		if column == nil || column.DataType != "string" {
+			return fmt.Errorf("x-fulltext of %s: %s is not a string property of the table", t.Name, property)
+		}
+		documents = append(documents, fmt.Sprintf("coalesce(%s, '')", quoteIdentifier(column.Name)))
+	}
+
+	t.ColumnDefinition = append(t.ColumnDefinition, Column{
+		Name:      searchVectorColumn,
+		DataType:  "string",
+		SQLType:   "TSVECTOR",
+		Generated: fmt.Sprintf("to_tsvector(%s, %s)", quoteLiteral(language), strings.Join(documents, " || ' ' || ")),
+		GinIndex:  true,
+	})
+	t.FullTextLanguage = language
+	return nil
+}
+
+// SearchQuery returns the sqlc query searching the table with its x-fulltext search_vector, or "" when the
+// table has no full-text search
+func (t Table) SearchQuery() string {
+	if t.FullTextLanguage == "" {
+		return ""
+	}
+
+	query := fmt.Sprintf("websearch_to_tsquery(%s, $1)", quoteLiteral(t.FullTextLanguage))
+
+	return fmt.Sprintf("-- name: Search%s :many\nSELECT %s FROM %s\nWHERE %s @@ %s\nORDER BY ts_rank(%s, %s) DESC;",
+		toPascalCase(t.Name), strings.Join(t.SelectColumns(), ", "), t.sqlName(), searchVectorColumn, query, searchVectorColumn, query)
+}
@@ -0,0 +1,71 @@
+package dbSchema
+
+import (
+	"fmt"
+	"slices"
+
+	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// Spatial reference system of GeoJSON coordinates (WGS 84, RFC 7946)
+const geoJSONSRID = 4326
+
+// GeoJSON geometries having coordinates, which are also the PostGIS geometry types
+var geoJSONGeometryTypes = []string{"Point", "MultiPoint", "LineString", "MultiLineString", "Polygon", "MultiPolygon"}
+
+// isGeoJSONSchema tells if an object schema is shaped like a GeoJSON geometry: a type whose values are
+// geometry types, e.g. enum: [Point], and coordinates which are numbers or nested arrays of numbers
+func isGeoJSONSchema(schema *highbase.Schema) bool {
+	if schema == nil || schema.Properties == nil {
+		return false
+	}
+	typeProperty, hasType := schema.Properties.Get("type")
+	coordinates, hasCoordinates := schema.Properties.Get("coordinates")
+	if !hasType || !hasCoordinates || !isCoordinatesSchema(coordinates.Schema()) {
+		return false
+	}
+
+	geometryTypes := geometryTypeValues(typeProperty.Schema())
+	return len(geometryTypes) > 0 && !slices.ContainsFunc(geometryTypes, func(geometryType string) bool {
+		return !slices.Contains(geoJSONGeometryTypes, geometryType)
+	})
+}
+
+// isCoordinatesSchema tells if a schema is an array of numbers, or of nested arrays of numbers
+func isCoordinatesSchema(schema *highbase.Schema) bool {
+	if schema == nil || !slices.Contains(schema.Type, "array") || schema.Items == nil || !schema.Items.IsA() {
+		return false
+	}
+	items := schema.Items.A.Schema()
+	return items != nil && (slices.Contains(items.Type, "number") || isCoordinatesSchema(items))
+}
+
+// geometryTypeValues returns the values of the type property of a GeoJSON geometry, from its const or enum
+func geometryTypeValues(typeSchema *highbase.Schema) []string {
+	if typeSchema == nil || !slices.Contains(typeSchema.Type, "string") {
+		return nil
+	}
+	if typeSchema.Const != nil {
+		return []string{typeSchema.Const.Value}
+	}
+
+	var values []string
+	for _, value := range typeSchema.Enum {
+		values = append(values, value.Value)
+	}
+	return values
+}
+
+// geometrySQLType returns the PostGIS type of a GeoJSON geometry. The geometry type is the single value of
+// the type property, e.g. Point, or any geometry when the type has several values.
+func geometrySQLType(schema *highbase.Schema) string {
+	geometryType := "Geometry"
+
+	if typeProperty, ok := schema.Properties.Get("type"); ok {
+		if geometryTypes := geometryTypeValues(typeProperty.Schema()); len(geometryTypes) == 1 {
+			geometryType = geometryTypes[0]
+		}
+	}
+
+	return fmt.Sprintf("GEOMETRY(%s,%d)", geometryType, geoJSONSRID)
+}
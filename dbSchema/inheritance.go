@@ -0,0 +1,335 @@
+package dbSchema
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// discriminatedVariants returns the oneOf / anyOf variants of a schema having a discriminator
+func discriminatedVariants(schema *highbase.Schema) []*highbase.SchemaProxy {
+	if schema.Discriminator == nil || schema.Discriminator.PropertyName == "" {
+		return nil
+	}
+	if schema.OneOf != nil {
+		return schema.OneOf
+	}
+	return schema.AnyOf
+}
+
+// schemaNameFromReference returns the component name of a reference like "#/components/schemas/Pet"
+func schemaNameFromReference(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// discriminatorValues returns the discriminator values identifying a variant. Without an explicit
+// mapping, the value is the name of the variant schema.
+func discriminatorValues(discriminator *highbase.Discriminator, variant *highbase.SchemaProxy) []string {
+	ref := variant.GetReference()
+	if ref == "" {
+		return nil
+	}
+
+	var values []string
+	if discriminator.Mapping != nil {
+		for mapping := discriminator.Mapping.First(); mapping != nil; mapping = mapping.Next() {
+			if mapping.Value() == ref || mapping.Value() == schemaNameFromReference(ref) {
+				values = append(values, mapping.Key())
+			}
+		}
+	}
+
+	if len(values) == 0 {
+		values = append(values, schemaNameFromReference(ref))
+	}
+
+	return values
+}
+
+// SingleTableVariants returns the component names of the variants stored in the table of a
+// discriminated oneOf / anyOf schema. These variants do not get a table of their own.
+func SingleTableVariants(schema *highbase.Schema) []string {
+	var names []string
+	for _, variant := range discriminatedVariants(schema) {
+		if ref := variant.GetReference(); ref != "" {
+			names = append(names, schemaNameFromReference(ref))
+		}
+	}
+	return names
+}
+
+// singleTableParent is the component schema storing a variant of its discriminated oneOf / anyOf in its table
+type singleTableParent struct {
+	name   string
+	schema *highbase.Schema
+}
+
+// WithSingleTableVariants returns the options knowing the variants stored in the table of their parent,
+// so that a reference to a variant references the table of its parent
+func (o Options) WithSingleTableVariants(schemas *orderedmap.Map[string, *highbase.SchemaProxy]) Options {
+	o.singleTableParents = map[string]singleTableParent{}
+	for schema := schemas.First(); schema != nil; schema = schema.Next() {
+		for _, name := range SingleTableVariants(schema.Value().Schema()) {
+			o.singleTableParents[name] = singleTableParent{name: schema.Key(), schema: schema.Value().Schema()}
+		}
+	}
+	return o
+}
+
+// variantProperties returns the properties and the required fields of a variant, including the ones of
+// its allOf items. An allOf item referencing the parent is skipped, its columns are the shared ones.
+func variantProperties(variantSchema *highbase.Schema, parentName string) ([]*orderedmap.Map[string, *highbase.SchemaProxy], []string) {
+	properties := []*orderedmap.Map[string, *highbase.SchemaProxy]{variantSchema.Properties}
+	required := slices.Clone(variantSchema.Required)
+	for _, item := range variantSchema.AllOf {
+		if ref := item.GetReference(); ref != "" && schemaNameFromReference(ref) == parentName {
+			continue
+		}
+		if itemSchema := item.Schema(); itemSchema != nil {
+			properties = append(properties, itemSchema.Properties)
+			required = append(required, itemSchema.Required...)
+		}
+	}
+	return properties, required
+}
+
+// buildSingleTableInheritance stores every variant of a discriminated oneOf / anyOf in one table.
+// The discriminator column is an enum of the discriminator values, variant columns are nullable
+// and a CHECK constraint per variant enforces its required fields.
+func buildSingleTableInheritance(table *Table, tableName string, schema *highbase.Schema, options Options) error {
+	discriminator := schema.Discriminator
+	variants := discriminatedVariants(schema)
+
+	var discriminatorEnum []string
+	for _, variant := range variants {
+		discriminatorEnum = append(discriminatorEnum, discriminatorValues(discriminator, variant)...)
+	}
+
+	discriminatorColumnName := options.naming().ColumnName(discriminator.PropertyName)
+	enumType := enumTypeName(tableName, discriminator.PropertyName, "", options)
+	discriminatorColumn := Column{
+		Name:       discriminatorColumnName,
+		DataType:   "string",
+		SQLType:    enumType,
+		NotNull:    true,
+		customType: enumType,
+		Enum:       discriminatorEnum,
+	}
+
+	// Columns shared by all variants
+	if schema.Properties != nil {
+		for property := schema.Properties.First(); property != nil; property = property.Next() {
+			if !isDatabaseColumn(property.Value()) {
+				continue
+			}
+			if property.Key() == discriminator.PropertyName {
+				table.ColumnDefinition = append(table.ColumnDefinition, discriminatorColumn)
+				continue
+			}
+
+			column, err := buildColumnFromProperty(tableName, property, schema.Required, options)
+			if err != nil {
+				return fmt.Errorf("could not build column for %s: %v", property.Key(), err)
+			}
+			table.ColumnDefinition = append(table.ColumnDefinition, column)
+		}
+	}
+
+	if table.column(discriminatorColumnName) == nil {
+		table.ColumnDefinition = append(table.ColumnDefinition, discriminatorColumn)
+	}
+
+	// Union of the variant columns, made nullable
+	for _, variant := range variants {
+		variantSchema := variant.Schema()
+		if variantSchema == nil {
+			continue
+		}
+
+		var requiredColumns []string
+		propertyMaps, required := variantProperties(variantSchema, tableName)
+		for _, properties := range propertyMaps {
+			if properties == nil {
+				continue
+			}
+			for property := properties.First(); property != nil; property = property.Next() {
+				if !isDatabaseColumn(property.Value()) {
+					continue
+				}
+
+				column, err := buildColumnFromProperty(tableName, property, nil, options)
+				if err != nil {
+					return fmt.Errorf("could not build column for %s: %v", property.Key(), err)
+				}
+
+				if property.Key() != discriminator.PropertyName && slices.Contains(required, property.Key()) && !slices.Contains(requiredColumns, column.Name) {
+					requiredColumns = append(requiredColumns, column.Name)
+				}
+
+				if table.column(column.Name) == nil {
+					table.ColumnDefinition = append(table.ColumnDefinition, column)
+				}
+			}
+		}
+
+		if check := variantRequiredCheck(discriminatorColumnName, discriminatorValues(discriminator, variant), requiredColumns); check != "" {
+			table.CheckConstraints = append(table.CheckConstraints, check)
+		}
+	}
+
+	return nil
+}
+
+// variantRequiredCheck returns the condition enforcing the required columns of a variant
+func variantRequiredCheck(discriminatorColumn string, values []string, requiredColumns []string) string {
+	if len(values) == 0 || len(requiredColumns) == 0 {
+		return ""
+	}
+
+	var conditions []string
+	for _, column := range requiredColumns {
+		conditions = append(conditions, fmt.Sprintf("%s IS NOT NULL", column))
+	}
+
+	var quotedValues []string
+	for _, value := range values {
+		quotedValues = append(quotedValues, quoteLiteral(value))
+	}
+
+	var variantCondition string
+	if len(values) == 1 {
+		variantCondition = fmt.Sprintf("%s <> %s", discriminatorColumn, quotedValues[0])
+	} else {
+		variantCondition = fmt.Sprintf("%s NOT IN (%s)", discriminatorColumn, strings.Join(quotedValues, ", "))
+	}
+
+	return fmt.Sprintf("%s OR (%s)", variantCondition, strings.Join(conditions, " AND "))
+}
+
+// Strategies mapping an allOf referencing a parent schema, chosen with the x-inheritance extension
+const (
+	InheritanceFlatten    = "flatten"     // The parent columns are copied into the child table (default)
+	InheritanceJoined     = "joined"      // The child table only has its own columns and an id referencing the parent table
+	InheritancePgInherits = "pg-inherits" // The child table inherits from the parent table with INHERITS
+)
+
+// inheritanceStrategy reads the x-inheritance extension of a schema, or of the parent it references in allOf
+func inheritanceStrategy(schema *highbase.Schema) (string, error) {
+	strategy, ok := extensionValue(schema, "x-inheritance")
+	if !ok {
+		for _, item := range schema.AllOf {
+			if item.GetReference() == "" {
+				continue
+			}
+			if strategy, ok = extensionValue(item.Schema(), "x-inheritance"); ok {
+				break
+			}
+		}
+	}
+
+	switch strategy {
+	case "":
+		return InheritanceFlatten, nil
+	case InheritanceFlatten, InheritanceJoined, InheritancePgInherits:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown x-inheritance strategy: %s", strategy)
+	}
+}
+
+// buildAllOfInheritance builds the child table of an allOf with the joined or pg-inherits strategy.
+// With joined, the first referenced schema is the parent table and other references are flattened.
+// With pg-inherits, every referenced schema is a parent table.
+func buildAllOfInheritance(table *Table, tableName string, schema *highbase.Schema, strategy string, options Options) error {
+	var parentTables []TableReference
+	var ownItems []*highbase.Schema
+
+	for _, item := range schema.AllOf {
+		itemSchema := item.Schema()
+		if itemSchema == nil {
+			continue
+		}
+
+		ref := item.GetReference()
+		if ref == "" || (strategy == InheritanceJoined && len(parentTables) > 0) {
+			ownItems = append(ownItems, itemSchema)
+			continue
+		}
+
+		parentTable, _ := referencedEntity(ref, itemSchema, options)
+		parentTables = append(parentTables, parentTable)
+
+		if strategy == InheritanceJoined {
+			idColumn, err := parentIdColumn(itemSchema, parentTable, options)
+			if err != nil {
+				return err
+			}
+			table.ColumnDefinition = append(table.ColumnDefinition, idColumn)
+		}
+	}
+
+	if strategy == InheritancePgInherits {
+		table.Inherits = parentTables
+	}
+
+	requiredColumns := schema.Required
+	for _, item := range ownItems {
+		requiredColumns = append(requiredColumns, item.Required...)
+	}
+
+	for _, item := range ownItems {
+		if item.Properties == nil {
+			continue
+		}
+
+		columns, err := BuildColumnsFromSchema(tableName, *item.Properties, requiredColumns, options)
+		if err != nil {
+			return err
+		}
+
+		for _, column := range columns {
+			if table.column(column.Name) == nil {
+				table.ColumnDefinition = append(table.ColumnDefinition, column)
+			}
+		}
+	}
+
+	return nil
+}
+
+// parentIdColumn returns the primary key of a joined child table, which references the parent table
+func parentIdColumn(parentSchema *highbase.Schema, parentTable TableReference, options Options) (Column, error) {
+	idColumn, ok, err := entityIdColumn(parentTable.Name, parentSchema, options)
+	if err != nil {
+		return Column{}, err
+	}
+	if !ok {
+		return Column{}, fmt.Errorf("joined inheritance requires the parent table %s to have an id", parentTable)
+	}
+
+	column := referencingIdColumn(idColumn, "id", parentTable)
+	column.PrimaryKey = true
+	return column, nil
+}
+
+// referencingIdColumn returns a NOT NULL column of the same type as the id of another table, referencing it
+func referencingIdColumn(idColumn Column, columnName string, referencedTable TableReference) Column {
+	column := idColumn
+	column.Name = columnName
+	column.NotNull = true
+	column.PrimaryKey = false
+	column.Unique = false
+	column.DefaultValue = ""
+	column.Identity = ""
+	column.ForeignKey = referencedTable
+
+	// A serial id is referenced with its underlying integer type
+	if integerType, ok := serialTypes[strings.ToLower(column.SQLType)]; ok {
+		column.SQLType = strings.ToUpper(integerType)
+	}
+
+	return column
+}
@@ -0,0 +1,106 @@
+package dbSchema
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// Strategies generating the values of a key column, chosen with the idStrategy / uuidStrategy
+// options or the x-id-strategy extension
+const (
+	IDStrategyIdentityAlways    = "identity-always"     // GENERATED ALWAYS AS IDENTITY
+	IDStrategyIdentityByDefault = "identity-by-default" // GENERATED BY DEFAULT AS IDENTITY (default for integer ids)
+	IDStrategySerial            = "serial"              // SERIAL or BIGSERIAL, depending on the integer format
+	IDStrategyUUIDv4            = "uuidv4"              // DEFAULT gen_random_uuid() (default for uuid ids)
+	IDStrategyUUIDv7            = "uuidv7"              // DEFAULT uuidv7(), available since PostgreSQL 18
+	IDStrategyNone              = "none"                // Values are given by the application
+)
+
+var integerIDStrategies = []string{IDStrategyIdentityAlways, IDStrategyIdentityByDefault, IDStrategySerial, IDStrategyNone}
+var uuidIDStrategies = []string{IDStrategyUUIDv4, IDStrategyUUIDv7, IDStrategyNone}
+
+// idStrategy returns the strategy generating the values of a column, or "" when its values are not generated.
+// Integer and uuid ids are generated, as well as columns having x-id-strategy or x-autoincrement: true.
+func idStrategy(columnSchema *highbase.Schema, column Column, options Options) (string, error) {
+	isUUID := column.DataType == "string" && column.DataFormat == "uuid"
+
+	strategy, ok := extensionValue(columnSchema, "x-id-strategy")
+	if !ok {
+		autoincrement, hasAutoincrement := extensionValue(columnSchema, "x-autoincrement")
+		switch {
+		case hasAutoincrement && autoincrement == "false":
+			return "", nil
+		case !hasAutoincrement && column.Name != "id":
+			return "", nil
+		case !hasAutoincrement && column.DataType != "integer" && !isUUID:
+			return "", nil
+		}
+
+		strategy = options.IDStrategy
+		if isUUID {
+			strategy = options.UUIDStrategy
+		}
+	}
+
+	allowedStrategies := integerIDStrategies
+	if isUUID {
+		allowedStrategies = uuidIDStrategies
+	}
+	if strategy == "" && isUUID {
+		strategy = IDStrategyUUIDv4
+	} else if strategy == "" {
+		strategy = IDStrategyIdentityByDefault
+	}
+
+	if !slices.Contains(allowedStrategies, strategy) || (column.DataType != "integer" && !isUUID) {
+		return "", fmt.Errorf("id strategy %s cannot generate the values of %s (%s %s)", strategy, column.Name, column.DataType, column.DataFormat)
+	}
+
+	return strategy, nil
+}
+
+// applyIDStrategy makes the column generate its values with a strategy
+func (c *Column) applyIDStrategy(strategy string) {
+	if strategy == "" {
+		return
+	}
+	c.NotNull = true
+
+	switch strategy {
+	case IDStrategyIdentityAlways:
+		c.Identity = "ALWAYS"
+	case IDStrategyIdentityByDefault:
+		c.Identity = "BY DEFAULT"
+	case IDStrategySerial:
+		for serialType, integerType := range serialTypes {
+			if strings.EqualFold(c.SQLType, integerType) {
+				c.SQLType = strings.ToUpper(serialType)
+			}
+		}
+	case IDStrategyUUIDv4:
+		if c.DefaultValue == "" {
+			c.DefaultValue = "gen_random_uuid()"
+		}
+	case IDStrategyUUIDv7:
+		if c.DefaultValue == "" {
+			c.DefaultValue = "uuidv7()"
+		}
+	}
+}
+
+// entityIdColumn builds the id column of the table of an entity schema. ok is false when the entity has no id property.
+func entityIdColumn(tableName string, schema *highbase.Schema, options Options) (column Column, ok bool, err error) {
+	if schema == nil || schema.Properties == nil {
+		return Column{}, false, nil
+	}
+	for property := schema.Properties.First(); property != nil; property = property.Next() {
+		if property.Key() == "id" {
+			column, err := buildColumnFromProperty(tableName, property, nil, options)
+			return column, true, err
+		}
+	}
+	return Column{}, false, nil
+}
@@ -0,0 +1,228 @@
+package dbSchema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+	"gopkg.in/yaml.v3"
+)
+
+// quoteLiteral renders a string as a SQL string literal, escaping single quotes
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// sqlLiteral renders a value of a given OpenAPI type as a SQL literal. Numbers and booleans are parsed
+// and rendered again, so that a value cannot change the statement it is written in.
+func sqlLiteral(dataType string, value string) (string, error) {
+	switch dataType {
+	case "integer", "number":
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsInf(number, 0) || math.IsNaN(number) || (dataType == "integer" && number != math.Trunc(number)) {
+			return "", fmt.Errorf("%s is not a valid %s", value, dataType)
+		}
+		return formatNumber(number), nil
+	case "boolean":
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%s is not a boolean", value)
+		}
+		return strings.ToUpper(strconv.FormatBool(boolean)), nil
+	default:
+		return quoteLiteral(value), nil
+	}
+}
+
+// Layouts of the string formats whose default values are checked
+var dateLayouts = map[string]string{
+	"date":      time.DateOnly,
+	"date-time": time.RFC3339,
+}
+
+// defaultExpression renders the default of a property as a literal of the column type. Defaults of the
+// wrong type or violating the constraints of the property are rejected.
+func defaultExpression(column Column, schema *highbase.Schema) (string, error) {
+	if schema.Default == nil {
+		return "", nil
+	}
+
+	expression, err := literalFromNode(column.DataType, column.SQLType, schema, schema.Default)
+	if err != nil {
+		return "", fmt.Errorf("invalid default for %s: %v", column.Name, err)
+	}
+	return expression, nil
+}
+
+// literalFromNode renders a YAML value as a SQL literal, after checking it against its schema
+func literalFromNode(dataType string, sqlType string, schema *highbase.Schema, node *yaml.Node) (string, error) {
+	switch dataType {
+	case "boolean", "integer", "number":
+		if node.Kind != yaml.ScalarNode {
+			return "", fmt.Errorf("%s is not a valid %s", node.Value, dataType)
+		}
+		literal, err := sqlLiteral(dataType, node.Value)
+		if err != nil {
+			return "", err
+		}
+		if dataType != "boolean" {
+			value, _ := strconv.ParseFloat(node.Value, 64)
+			if err := checkNumber(schema, value); err != nil {
+				return "", err
+			}
+		}
+		return literal, nil
+
+	case "string":
+		if node.Kind != yaml.ScalarNode {
+			return "", fmt.Errorf("%s is not a string", node.Value)
+		}
+		if err := checkString(schema, node.Value); err != nil {
+			return "", err
+		}
+		return quoteLiteral(node.Value), nil
+
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			return "", fmt.Errorf("the default of an array must be a list")
+		}
+		if schema.MinItems != nil && int64(len(node.Content)) < *schema.MinItems {
+			return "", fmt.Errorf("less than %d items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && int64(len(node.Content)) > *schema.MaxItems {
+			return "", fmt.Errorf("more than %d items", *schema.MaxItems)
+		}
+
+		// Native arrays
+		if strings.HasSuffix(sqlType, "[]") && schema.Items != nil && schema.Items.IsA() {
+			itemSchema := schema.Items.A.Schema()
+			if len(node.Content) == 0 {
+				return "'{}'", nil
+			}
+
+			var items []string
+			for _, item := range node.Content {
+				literal, err := literalFromNode(itemSchema.Type[0], "", itemSchema, item)
+				if err != nil {
+					return "", err
+				}
+				items = append(items, literal)
+			}
+			return fmt.Sprintf("ARRAY[%s]", strings.Join(items, ", ")), nil
+		}
+		return jsonLiteral(node)
+
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			return "", fmt.Errorf("the default of an object must be a map")
+		}
+		return jsonLiteral(node)
+	}
+
+	return "", fmt.Errorf("no default for the type %s", dataType)
+}
+
+// jsonLiteral renders a YAML value as a JSON string literal
+func jsonLiteral(node *yaml.Node) (string, error) {
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return "", err
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return quoteLiteral(string(encoded)), nil
+}
+
+// checkNumber checks a number against the numeric validation keywords of its schema
+func checkNumber(schema *highbase.Schema, value float64) error {
+	if schema.Minimum != nil && value < *schema.Minimum {
+		return fmt.Errorf("%s is less than the minimum %s", formatNumber(value), formatNumber(*schema.Minimum))
+	}
+	if schema.Maximum != nil && value > *schema.Maximum {
+		return fmt.Errorf("%s is greater than the maximum %s", formatNumber(value), formatNumber(*schema.Maximum))
+	}
+
+	var exclusiveMinimum, exclusiveMaximum *float64
+	if bound := schema.ExclusiveMinimum; bound != nil && bound.IsA() && bound.A {
+		exclusiveMinimum = schema.Minimum
+	} else if bound != nil && bound.IsB() {
+		exclusiveMinimum = &bound.B
+	}
+	if bound := schema.ExclusiveMaximum; bound != nil && bound.IsA() && bound.A {
+		exclusiveMaximum = schema.Maximum
+	} else if bound != nil && bound.IsB() {
+		exclusiveMaximum = &bound.B
+	}
+
+	if exclusiveMinimum != nil && value <= *exclusiveMinimum {
+		return fmt.Errorf("%s is not greater than %s", formatNumber(value), formatNumber(*exclusiveMinimum))
+	}
+	if exclusiveMaximum != nil && value >= *exclusiveMaximum {
+		return fmt.Errorf("%s is not less than %s", formatNumber(value), formatNumber(*exclusiveMaximum))
+	}
+
+	if schema.MultipleOf != nil && *schema.MultipleOf != 0 {
+		quotient := value / *schema.MultipleOf
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			return fmt.Errorf("%s is not a multiple of %s", formatNumber(value), formatNumber(*schema.MultipleOf))
+		}
+	}
+
+	return checkValues(schema, formatNumber(value), func(node *yaml.Node) string {
+		parsed, _ := strconv.ParseFloat(node.Value, 64)
+		return formatNumber(parsed)
+	})
+}
+
+// checkString checks a string against the string validation keywords of its schema
+func checkString(schema *highbase.Schema, value string) error {
+	length := int64(utf8.RuneCountInString(value))
+	if schema.MinLength != nil && length < *schema.MinLength {
+		return fmt.Errorf("%q is shorter than %d characters", value, *schema.MinLength)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		return fmt.Errorf("%q is longer than %d characters", value, *schema.MaxLength)
+	}
+
+	// Patterns which are not valid Go regular expressions are only checked by PostgreSQL
+	if pattern, err := regexp.Compile(schema.Pattern); schema.Pattern != "" && err == nil && !pattern.MatchString(value) {
+		return fmt.Errorf("%q does not match the pattern %s", value, schema.Pattern)
+	}
+
+	if layout, ok := dateLayouts[schema.Format]; ok {
+		if _, err := time.Parse(layout, value); err != nil {
+			return fmt.Errorf("%q is not a %s", value, schema.Format)
+		}
+	}
+
+	return checkValues(schema, value, func(node *yaml.Node) string { return node.Value })
+}
+
+// checkValues checks a value is one of the enum values and equal to the const of its schema
+func checkValues(schema *highbase.Schema, value string, normalize func(*yaml.Node) string) error {
+	if len(schema.Enum) > 0 {
+		var values []string
+		for _, item := range schema.Enum {
+			values = append(values, normalize(item))
+		}
+		if !slices.Contains(values, value) {
+			return fmt.Errorf("%s is not one of %s", value, strings.Join(values, ", "))
+		}
+	}
+
+	if schema.Const != nil && normalize(schema.Const) != value {
+		return fmt.Errorf("%s is not the const %s", value, schema.Const.Value)
+	}
+
+	return nil
+}
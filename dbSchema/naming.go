@@ -0,0 +1,126 @@
+package dbSchema
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/jinzhu/inflection"
+)
+
+// NamingStrategy names the tables, columns and types generated from the component schemas and their
+// properties. Set Options.NamingStrategy to use a custom one.
+type NamingStrategy interface {
+	TableName(schemaName string) string    // Table of a component schema, e.g. orders for Order
+	ColumnName(propertyName string) string // Column of a property
+	TypeName(schemaName string) string     // Enum, domain or composite type named after a component schema
+	Singular(tableName string) string      // Singular of a table name, prefixing foreign keys and types
+}
+
+// Conventions of the column names, chosen with the naming.columns option
+const (
+	ColumnNamingPreserve  = "preserve"   // Columns are named like their properties, e.g. photoUrls (default)
+	ColumnNamingSnakeCase = "snake_case" // photoUrls -> photo_urls
+)
+
+// NamingOptions configure the default naming strategy
+type NamingOptions struct {
+	// Convention of the column names: preserve (default) or snake_case
+	Columns string `yaml:"columns"`
+	// Plural table names (default), or singular ones when false
+	Pluralize *bool `yaml:"pluralize"`
+	// Irregular plurals of the last word of table names, e.g. person: people
+	Plurals map[string]string `yaml:"plurals"`
+}
+
+// DefaultNaming names tables and types in snake_case, with plural table names
+type DefaultNaming struct {
+	NamingOptions
+}
+
+func (n DefaultNaming) TableName(schemaName string) string {
+	name := toSnakeCase(schemaName)
+	if n.Pluralize != nil && !*n.Pluralize {
+		return name
+	}
+
+	prefix, word := splitLastWord(name)
+	if plural, ok := n.Plurals[word]; ok {
+		return prefix + plural
+	}
+	return inflection.Plural(name)
+}
+
+func (n DefaultNaming) ColumnName(propertyName string) string {
+	if n.Columns == ColumnNamingSnakeCase {
+		return toSnakeCase(propertyName)
+	}
+	return propertyName
+}
+
+func (n DefaultNaming) TypeName(schemaName string) string {
+	return toSnakeCase(schemaName)
+}
+
+func (n DefaultNaming) Singular(tableName string) string {
+	if n.Pluralize != nil && !*n.Pluralize {
+		return tableName
+	}
+
+	prefix, word := splitLastWord(tableName)
+	for singular, plural := range n.Plurals {
+		if plural == word {
+			return prefix + singular
+		}
+	}
+	return inflection.Singular(tableName)
+}
+
+// splitLastWord splits a snake_case name before its last word
+func splitLastWord(name string) (string, string) {
+	index := strings.LastIndex(name, "_") + 1
+	return name[:index], name[index:]
+}
+
+// naming returns the naming strategy of the options
+func (o Options) naming() NamingStrategy {
+	if o.NamingStrategy != nil {
+		return o.NamingStrategy
+	}
+	return DefaultNaming{o.Naming}
+}
+
+// toSnakeCase converts a camelCase or PascalCase name to snake_case. Acronyms are kept in one word,
+// e.g. HTTPRequest -> http_request and userID -> user_id.
+func toSnakeCase(s string) string {
+	runes := []rune(s)
+	var sb strings.Builder
+
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && runes[i-1] != '_' {
+			previous := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// The s of a plural acronym is part of it, e.g. photoURLs -> photo_urls
+			pluralAcronym := nextIsLower && runes[i+1] == 's' && (i+2 == len(runes) || !unicode.IsLower(runes[i+2]))
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower && !pluralAcronym) {
+				sb.WriteRune('_')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+
+	return sb.String()
+}
+
+// toPascalCase converts a snake_case name to PascalCase, e.g. blog_posts -> BlogPosts
+func toPascalCase(s string) string {
+	var sb strings.Builder
+	for _, word := range strings.Split(s, "_") {
+		if word == "" {
+			continue
+		}
+		runes := []rune(word)
+		sb.WriteRune(unicode.ToUpper(runes[0]))
+		sb.WriteString(string(runes[1:]))
+	}
+	return sb.String()
+}
@@ -0,0 +1,177 @@
+package dbSchema
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// Storages of an inline object property, chosen with the x-storage extension.
+// Without extension, the object is stored in a JSON column.
+const (
+	StorageTable     = "table"     // Child table with a foreign key back to the owner table
+	StorageJSONB     = "jsonb"     // JSONB column, checked against the object schema with x-json-schema-check
+	StorageFlatten   = "flatten"   // One column per object property, prefixed with the property name
+	StorageComposite = "composite" // Column of a composite type, created once per object schema
+)
+
+// JSON types returned by jsonb_typeof for each OpenAPI type
+var jsonbTypeofMap = map[string]string{
+	"string":  "string",
+	"integer": "number",
+	"number":  "number",
+	"boolean": "boolean",
+	"array":   "array",
+	"object":  "object",
+}
+
+// nestedObjectStorage returns the x-storage of an inline object property, or "" when it is not one
+func nestedObjectStorage(property *highbase.SchemaProxy) (string, error) {
+	if property.GetReference() != "" {
+		return "", nil
+	}
+
+	schema := property.Schema()
+	if schema == nil || len(schema.Type) == 0 || schema.Type[0] != "object" || schema.Properties == nil {
+		return "", nil
+	}
+
+	storage, _ := extensionValue(schema, "x-storage")
+	switch storage {
+	case "", StorageTable, StorageJSONB, StorageFlatten, StorageComposite:
+		return storage, nil
+	default:
+		// Storage of the column of the object, see applyOverrides
+		if slices.Contains(columnStorages, strings.ToLower(storage)) {
+			return "", nil
+		}
+		return "", fmt.Errorf("unknown x-storage for an object: %s", storage)
+	}
+}
+
+// buildFlattenedColumns builds one column per property of a nested object, named <property>_<nested property>.
+// Nested columns can only be NOT NULL when the object itself is required.
+func buildFlattenedColumns(tableName string, property orderedmap.Pair[string, *highbase.SchemaProxy], requiredColumns []string, options Options) ([]Column, error) {
+	objectSchema := property.Value().Schema()
+
+	columns, err := BuildColumnsFromSchema(tableName, *objectSchema.Properties, objectSchema.Required, options)
+	if err != nil {
+		return nil, err
+	}
+
+	required := slices.Contains(requiredColumns, property.Key())
+	for i := range columns {
+		columns[i].Name = options.naming().ColumnName(property.Key()) + "_" + columns[i].Name
+		columns[i].NotNull = columns[i].NotNull && required
+		columns[i].PrimaryKey = false
+		if len(columns[i].Enum) > 0 {
+			columns[i].customType = enumTypeName(tableName, columns[i].Name, "", options)
+			if columns[i].DataType == "string" {
+				columns[i].SQLType = columns[i].customType
+			}
+		}
+	}
+
+	return columns, nil
+}
+
+// buildJSONBColumn stores a nested object in a JSONB column. With x-json-schema-check, a CHECK
+// constraint enforces the object type, its required keys and the JSON type of its properties.
+func buildJSONBColumn(tableName string, property orderedmap.Pair[string, *highbase.SchemaProxy], requiredColumns []string, options Options) (Column, error) {
+	column, err := buildColumnFromProperty(tableName, property, requiredColumns, options)
+	if err != nil {
+		return Column{}, err
+	}
+	column.SQLType = "JSONB"
+
+	objectSchema := property.Value().Schema()
+	if val, ok := extensionValue(objectSchema, "x-json-schema-check"); ok && val == "true" {
+		column.Constraints = append(column.Constraints, CheckConstraint{Expression: jsonSchemaCheck(column.Name, objectSchema)})
+	}
+
+	return column, nil
+}
+
+func jsonSchemaCheck(columnName string, objectSchema *highbase.Schema) string {
+	conditions := []string{fmt.Sprintf("jsonb_typeof(%s) = 'object'", columnName)}
+
+	if len(objectSchema.Required) > 0 {
+		var keys []string
+		for _, key := range objectSchema.Required {
+			keys = append(keys, quoteLiteral(key))
+		}
+		conditions = append(conditions, fmt.Sprintf("%s ?& ARRAY[%s]", columnName, strings.Join(keys, ", ")))
+	}
+
+	for property := objectSchema.Properties.First(); property != nil; property = property.Next() {
+		propertySchema := property.Value().Schema()
+		if propertySchema == nil || len(propertySchema.Type) == 0 {
+			continue
+		}
+
+		if jsonType, ok := jsonbTypeofMap[propertySchema.Type[0]]; ok {
+			key := quoteLiteral(property.Key())
+			conditions = append(conditions, fmt.Sprintf("(NOT %s ? %s OR jsonb_typeof(%s->%s) = '%s')", columnName, key, columnName, key, jsonType))
+		}
+	}
+
+	return strings.Join(conditions, " AND ")
+}
+
+// buildNestedTables builds the child tables of the nested objects stored with x-storage: table.
+// A child table is named <owner>_<property> and its primary key references the owner id.
+func (t *Table) buildNestedTables(properties *orderedmap.Map[string, *highbase.SchemaProxy], options Options) error {
+	if properties == nil {
+		return nil
+	}
+
+	for property := properties.First(); property != nil; property = property.Next() {
+		storage, err := nestedObjectStorage(property.Value())
+		if err != nil {
+			return err
+		}
+		if storage != StorageTable || !isDatabaseColumn(property.Value()) {
+			continue
+		}
+
+		ownerId := t.column("id")
+		if ownerId == nil {
+			return fmt.Errorf("x-storage: table requires the table %s to have an id", t.Name)
+		}
+
+		naming := options.naming()
+		ownerColumnName := naming.Singular(t.Name) + "_id"
+		childTable, err := BuildTableFromSchema(naming.Singular(t.Name)+"_"+naming.ColumnName(property.Key()), property.Value().Schema(), options)
+		if err != nil {
+			return err
+		}
+		if len(childTable.ColumnDefinition) == 0 {
+			return fmt.Errorf("could not build the table of %s", property.Key())
+		}
+
+		// Child tables are placed with their owner table, unless the object sets its own x-schema / x-tablespace
+		if childTable.Schema == "" {
+			childTable.Schema = t.Schema
+		}
+		if childTable.Tablespace == "" {
+			childTable.Tablespace = t.Tablespace
+		}
+
+		// One row per owner: the owner id is the primary key unless the object has its own id
+		ownerColumn := referencingIdColumn(*ownerId, ownerColumnName, t.reference())
+		ownerColumn.PrimaryKey = childTable.column("id") == nil
+		ownerColumn.Unique = !ownerColumn.PrimaryKey
+		childTable.ColumnDefinition = append([]Column{ownerColumn}, childTable.ColumnDefinition...)
+
+		// Tables nested in the child table are listed after it
+		grandChildTables := childTable.ChildTables
+		childTable.ChildTables = nil
+		t.ChildTables = append(t.ChildTables, *childTable)
+		t.ChildTables = append(t.ChildTables, grandChildTables...)
+	}
+
+	return nil
+}
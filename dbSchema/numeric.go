@@ -0,0 +1,82 @@
+package dbSchema
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// MultipleOfConstraint checks the multipleOf of the columns whose type does not enforce it
+type MultipleOfConstraint struct {
+	MultipleOf *float64
+}
+
+func (mc MultipleOfConstraint) GetConstraint(columnName string) []string {
+	if mc.MultipleOf != nil {
+		return []string{fmt.Sprintf("mod(%s, %s) = 0", columnName, formatNumber(*mc.MultipleOf))}
+	}
+	return []string{}
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// numericTypeModifiers returns the precision and scale of a NUMERIC column. The scale comes from
+// multipleOf (0.01 -> 2) and the precision from the bounds and the scale; x-precision and x-scale
+// override them. ok is false when there is no precision, the column is then a plain NUMERIC.
+func numericTypeModifiers(schema *highbase.Schema) (precision int, scale int, ok bool, err error) {
+	precision, scale = -1, -1
+
+	if schema.MultipleOf != nil {
+		scale = 0
+		if i := strings.Index(formatNumber(*schema.MultipleOf), "."); i >= 0 {
+			scale = len(formatNumber(*schema.MultipleOf)) - i - 1
+		}
+	}
+
+	if val, found := extensionValue(schema, "x-scale"); found {
+		if scale, err = strconv.Atoi(val); err != nil || scale < 0 {
+			return 0, 0, false, fmt.Errorf("invalid x-scale: %s", val)
+		}
+	}
+
+	var bound *float64
+	for _, value := range []*float64{schema.Minimum, schema.Maximum} {
+		if value != nil && (bound == nil || math.Abs(*value) > *bound) {
+			absolute := math.Abs(*value)
+			bound = &absolute
+		}
+	}
+	if bound != nil && scale >= 0 {
+		precision = len(formatNumber(math.Floor(*bound))) + scale
+	}
+
+	if val, found := extensionValue(schema, "x-precision"); found {
+		if precision, err = strconv.Atoi(val); err != nil || precision < 1 {
+			return 0, 0, false, fmt.Errorf("invalid x-precision: %s", val)
+		}
+	}
+
+	if precision < 0 {
+		if _, found := extensionValue(schema, "x-scale"); found {
+			return 0, 0, false, fmt.Errorf("x-scale requires x-precision or a maximum")
+		}
+		return 0, 0, false, nil
+	}
+
+	scale = max(scale, 0)
+	if scale > precision {
+		return 0, 0, false, fmt.Errorf("scale %d is greater than precision %d", scale, precision)
+	}
+
+	return precision, scale, true, nil
+}
+
+// enforcedByScale tells if the values of a NUMERIC(p, scale) are always a multiple of multipleOf
+func enforcedByScale(multipleOf float64, scale int) bool {
+	return formatNumber(multipleOf) == formatNumber(math.Pow10(-scale))
+}
@@ -0,0 +1,169 @@
+package dbSchema
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Options customize the generated schema. They are loaded from a YAML configuration file, e.g.:
+//
+//	jsonStorage: json
+//	timestampWithTimeZone: true
+//	auditColumns:
+//	  - name: created_at
+//	    notNull: true
+//	    default: NOW()
+//	  - name: modified_at
+//	    notNull: true
+//	    default: NOW()
+//	    onUpdate: true
+//	typeMappings:
+//	  "string:email": CITEXT
+//	  "string:": VARCHAR(255)
+//	naming:
+//	  columns: snake_case
+//	  plurals:
+//	    person: people
+type Options struct {
+	// Type of the objects and free-form properties stored as JSON: jsonb (default) or json
+	JSONStorage string `yaml:"jsonStorage"`
+	// Strategy generating integer ids: identity-by-default (default), identity-always, serial or none
+	IDStrategy string `yaml:"idStrategy"`
+	// Strategy generating uuid ids: uuidv4 (default), uuidv7 or none
+	UUIDStrategy string `yaml:"uuidStrategy"`
+	// Storage of string enums: type (default), check or table
+	EnumStrategy string `yaml:"enumStrategy"`
+	// Map date-time to TIMESTAMPTZ instead of TIMESTAMP
+	TimestampWithTimeZone bool `yaml:"timestampWithTimeZone"`
+	// Columns filled by the database, replacing the default created_at / updated_at / deleted_at
+	AuditColumns []AuditColumn `yaml:"auditColumns"`
+	// PostgreSQL types overriding the default mapping of an OpenAPI "type:format"
+	TypeMappings map[string]string `yaml:"typeMappings"`
+	// Configuration of the default naming strategy
+	Naming NamingOptions `yaml:"naming"`
+	// Naming strategy replacing the default one, when using the library
+	NamingStrategy NamingStrategy `yaml:"-"`
+
+	// Parents of the variants stored in a single table, see WithSingleTableVariants
+	singleTableParents map[string]singleTableParent
+}
+
+// AuditColumn describes a column whose definition does not come from the spec, e.g. created_at
+type AuditColumn struct {
+	Name string `yaml:"name"`
+	// PostgreSQL type, the date-time type when empty
+	Type    string `yaml:"type"`
+	NotNull bool   `yaml:"notNull"`
+	Default string `yaml:"default"`
+	// Set to NOW() by a trigger on every update, for a timestamp column
+	OnUpdate bool `yaml:"onUpdate"`
+}
+
+// Audit columns used when the configuration does not list any
+var defaultAuditColumns = []AuditColumn{
+	{Name: "created_at", NotNull: true, Default: "NOW()"},
+	{Name: "updated_at", NotNull: true, Default: "NOW()", OnUpdate: true},
+	{Name: "deleted_at"},
+}
+
+// JSON storages of the jsonStorage option
+const (
+	JSONStorageJSONB = "jsonb"
+	JSONStorageJSON  = "json"
+)
+
+// Extensions required by PostgreSQL types which are not built in
+var typeExtensions = map[string]string{
+	"CITEXT":    "citext",
+	"GEOGRAPHY": "postgis",
+	"GEOMETRY":  "postgis",
+}
+
+// LoadOptions reads the options of a YAML configuration file
+func LoadOptions(path string) (Options, error) {
+	var options Options
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return options, fmt.Errorf("cannot read configuration file: %v", err)
+	}
+
+	if err := yaml.Unmarshal(content, &options); err != nil {
+		return options, fmt.Errorf("cannot parse configuration file %s: %v", path, err)
+	}
+
+	switch options.JSONStorage {
+	case "", JSONStorageJSONB, JSONStorageJSON:
+	default:
+		return options, fmt.Errorf("unknown jsonStorage in %s: %s", path, options.JSONStorage)
+	}
+
+	switch options.EnumStrategy {
+	case "", EnumStrategyType, EnumStrategyCheck, EnumStrategyTable:
+	default:
+		return options, fmt.Errorf("unknown enumStrategy in %s: %s", path, options.EnumStrategy)
+	}
+
+	switch options.Naming.Columns {
+	case "", ColumnNamingPreserve, ColumnNamingSnakeCase:
+	default:
+		return options, fmt.Errorf("unknown naming.columns in %s: %s", path, options.Naming.Columns)
+	}
+
+	if options.IDStrategy != "" && !slices.Contains(integerIDStrategies, options.IDStrategy) {
+		return options, fmt.Errorf("unknown idStrategy in %s: %s", path, options.IDStrategy)
+	}
+
+	if options.UUIDStrategy != "" && !slices.Contains(uuidIDStrategies, options.UUIDStrategy) {
+		return options, fmt.Errorf("unknown uuidStrategy in %s: %s", path, options.UUIDStrategy)
+	}
+
+	return options, nil
+}
+
+// sqlType returns the PostgreSQL type of an OpenAPI type and format
+func (o Options) sqlType(dataType string, dataFormat string) (string, bool) {
+	if sqlType, ok := o.TypeMappings[dataType+":"+dataFormat]; ok {
+		return sqlType, true
+	}
+
+	sqlType, ok := datatypeMap[dataType+":"+dataFormat]
+	if sqlType == "JSON" && o.JSONStorage != JSONStorageJSON {
+		sqlType = "JSONB"
+	}
+	if sqlType == "TIMESTAMP" && o.TimestampWithTimeZone {
+		sqlType = "TIMESTAMPTZ"
+	}
+	return sqlType, ok
+}
+
+// auditColumn returns the audit column of a given name, if any
+func (o Options) auditColumn(name string) (AuditColumn, bool) {
+	auditColumns := o.AuditColumns
+	if auditColumns == nil {
+		auditColumns = defaultAuditColumns
+	}
+
+	for _, auditColumn := range auditColumns {
+		if auditColumn.Name == name {
+			if auditColumn.Type == "" {
+				auditColumn.Type, _ = o.sqlType("string", "date-time")
+			}
+			return auditColumn, true
+		}
+	}
+	return AuditColumn{}, false
+}
+
+// createExtensionStatement returns the statement enabling the extension a type depends on, if any
+func createExtensionStatement(sqlType string) string {
+	baseType, _, _ := strings.Cut(strings.ToUpper(strings.TrimSuffix(sqlType, "[]")), "(")
+	if extension, ok := typeExtensions[baseType]; ok {
+		return fmt.Sprintf("CREATE EXTENSION IF NOT EXISTS %s;", extension)
+	}
+	return ""
+}
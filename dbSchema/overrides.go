@@ -0,0 +1,166 @@
+package dbSchema
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// Storages of a column, chosen with the x-storage extension of a property which is not a nested object
+var columnStorages = []string{"plain", "external", "extended", "main"}
+
+// Compression methods of a column, chosen with the x-compression extension
+var columnCompressions = []string{"pglz", "lz4"}
+
+// Extensions overriding the column derived from a property
+var overrideExtensions = []string{"x-sql-type", "x-column-name", "x-sql-default", "x-collation", "x-storage", "x-compression", "x-generated"}
+
+// applyOverrides applies the extensions of a property overriding its column: x-column-name, x-sql-default
+// (raw expression), x-collation, x-storage, x-compression and x-generated. x-sql-type is applied when
+// resolving the type.
+func (c *Column) applyOverrides(schema *highbase.Schema) error {
+	if name, ok := extensionValue(schema, "x-column-name"); ok {
+		c.Name = name
+	}
+	if expression, ok := extensionValue(schema, "x-sql-default"); ok {
+		if err := validateExpression(expression); err != nil {
+			return fmt.Errorf("invalid x-sql-default for %s: %v", c.Name, err)
+		}
+		c.DefaultValue = expression
+	}
+	// Generated columns have no default and no identity
+	if expression, ok := extensionValue(schema, "x-generated"); ok {
+		if err := validateExpression(expression); err != nil {
+			return fmt.Errorf("invalid x-generated for %s: %v", c.Name, err)
+		}
+		c.Generated = expression
+		c.DefaultValue = ""
+		c.Identity = ""
+	}
+	if collation, ok := extensionValue(schema, "x-collation"); ok {
+		c.Collation = collation
+	}
+
+	// Nested objects use x-storage to choose how they are stored, see nestedObjectStorage
+	if storage, ok := extensionValue(schema, "x-storage"); ok && !isObjectStorage(storage) {
+		if !slices.Contains(columnStorages, strings.ToLower(storage)) {
+			return fmt.Errorf("unknown x-storage for %s: %s", c.Name, storage)
+		}
+		c.Storage = strings.ToUpper(storage)
+	}
+
+	if compression, ok := extensionValue(schema, "x-compression"); ok {
+		if !slices.Contains(columnCompressions, strings.ToLower(compression)) {
+			return fmt.Errorf("unknown x-compression for %s: %s", c.Name, compression)
+		}
+		c.Compression = strings.ToLower(compression)
+	}
+
+	return nil
+}
+
+// isObjectStorage tells if an x-storage value is a storage of a nested object
+func isObjectStorage(storage string) bool {
+	return storage == StorageTable || storage == StorageJSONB || storage == StorageFlatten || storage == StorageComposite
+}
+
+// hasOverrides tells if a property overrides its column with raw SQL
+func hasOverrides(schema *highbase.Schema) bool {
+	return slices.ContainsFunc(overrideExtensions, func(extension string) bool {
+		_, ok := extensionValue(schema, extension)
+		return ok
+	})
+}
+
+// validateColumnDefinition checks with the PostgreSQL parser that a column definition is valid and
+// defines the column only: a table with exactly this column, and nothing else.
+func validateColumnDefinition(column Column) error {
+	definition, err := column.CreateSQLStatement()
+	if err != nil {
+		return err
+	}
+
+	statement, err := parseSingleStatement(fmt.Sprintf("CREATE TABLE t (%s)", definition))
+	if err != nil {
+		return fmt.Errorf("invalid column definition %q: %v", definition, err)
+	}
+	createStmt := statement.GetCreateStmt()
+	if createStmt == nil || len(createStmt.TableElts) != 1 {
+		return fmt.Errorf("invalid column definition %q: not a single column", definition)
+	}
+	columnDef := createStmt.TableElts[0].GetColumnDef()
+	createStmt.TableElts = nil
+	if columnDef == nil || columnDef.Colname != identifierName(column.Name) || !sameStatement(statement, "CREATE TABLE t ()") {
+		return fmt.Errorf("invalid column definition %q: not a single column", definition)
+	}
+	return nil
+}
+
+// validateTypeName checks that an x-sql-type is a single type name
+func validateTypeName(sqlType string) error {
+	statement, err := parseSingleStatement("SELECT NULL::" + sqlType)
+	if err != nil {
+		return err
+	}
+	target := selectTarget(statement)
+	if target == nil || target.GetTypeCast() == nil || target.GetTypeCast().Arg.GetAConst() == nil || !target.GetTypeCast().Arg.GetAConst().Isnull {
+		return fmt.Errorf("%q is not a type name", sqlType)
+	}
+	return nil
+}
+
+// validateExpression checks that an x-sql-default or x-generated is a single expression
+func validateExpression(expression string) error {
+	statement, err := parseSingleStatement("SELECT " + expression)
+	if err != nil {
+		return err
+	}
+	if selectTarget(statement) == nil {
+		return fmt.Errorf("%q is not a single expression", expression)
+	}
+	return nil
+}
+
+// parseSingleStatement parses a query which must be a single statement
+func parseSingleStatement(query string) (*pg_query.Node, error) {
+	result, err := pg_query.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Stmts) != 1 {
+		return nil, fmt.Errorf("expected a single statement, got %d", len(result.Stmts))
+	}
+	return result.Stmts[0].Stmt, nil
+}
+
+// selectTarget returns the expression of a SELECT with a single unnamed target and no other clause
+func selectTarget(statement *pg_query.Node) *pg_query.Node {
+	selectStmt := statement.GetSelectStmt()
+	if selectStmt == nil || len(selectStmt.TargetList) != 1 {
+		return nil
+	}
+	target := selectStmt.TargetList[0].GetResTarget()
+	selectStmt.TargetList = nil
+	if target == nil || target.Name != "" || !sameStatement(statement, "SELECT") {
+		return nil
+	}
+	return target.Val
+}
+
+// sameStatement tells if a parsed statement is the statement of a query
+func sameStatement(statement *pg_query.Node, query string) bool {
+	expected, err := parseSingleStatement(query)
+	return err == nil && proto.Equal(statement, expected)
+}
+
+// identifierName returns the name of a SQL identifier as stored by PostgreSQL: unquoted identifiers are lowercased
+func identifierName(identifier string) string {
+	if len(identifier) >= 2 && strings.HasPrefix(identifier, `"`) && strings.HasSuffix(identifier, `"`) {
+		return strings.ReplaceAll(identifier[1:len(identifier)-1], `""`, `"`)
+	}
+	return strings.ToLower(identifier)
+}
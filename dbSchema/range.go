@@ -0,0 +1,46 @@
+package dbSchema
+
+import (
+	"fmt"
+	"strings"
+
+	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// Range types of each bound type
+var rangeTypes = map[string]string{
+	"INTEGER":     "INT4RANGE",
+	"BIGINT":      "INT8RANGE",
+	"NUMERIC":     "NUMRANGE",
+	"DATE":        "DATERANGE",
+	"TIMESTAMP":   "TSRANGE",
+	"TIMESTAMPTZ": "TSTZRANGE",
+}
+
+// isRangeSchema tells if an object schema is a range marked with x-range, with a start and an end
+func isRangeSchema(schema *highbase.Schema) bool {
+	if schema == nil || schema.Properties == nil {
+		return false
+	}
+	if val, ok := extensionValue(schema, "x-range"); !ok || val != "true" {
+		return false
+	}
+	_, hasStart := schema.Properties.Get("start")
+	_, hasEnd := schema.Properties.Get("end")
+	return hasStart && hasEnd
+}
+
+// rangeSQLType returns the range type of a range object, from the type of its start
+func rangeSQLType(schema *highbase.Schema, options Options) (string, error) {
+	start, _ := schema.Properties.Get("start")
+	startSchema := start.Schema()
+	if startSchema == nil || len(startSchema.Type) == 0 {
+		return "", fmt.Errorf("no data type found for the start of the range")
+	}
+
+	boundType, _ := options.sqlType(startSchema.Type[0], startSchema.Format)
+	if rangeType, ok := rangeTypes[strings.ToUpper(boundType)]; ok {
+		return rangeType, nil
+	}
+	return "", fmt.Errorf("no range type for bounds of type %s", startSchema.Type[0])
+}
@@ -0,0 +1,410 @@
+package dbSchema
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Names used by the PostgreSQL parser for built-in types, mapped to the name written in DDL
+var pgCatalogTypeNames = map[string]string{
+	"int2":        "smallint",
+	"int4":        "integer",
+	"int8":        "bigint",
+	"bool":        "boolean",
+	"float4":      "real",
+	"float8":      "double precision",
+	"numeric":     "numeric",
+	"varchar":     "varchar",
+	"bpchar":      "char",
+	"time":        "time",
+	"timetz":      "timetz",
+	"timestamp":   "timestamp",
+	"timestamptz": "timestamptz",
+	"interval":    "interval",
+}
+
+// Serial types and the integer type they are a shorthand for
+var serialTypes = map[string]string{
+	"smallserial": "smallint",
+	"serial":      "integer",
+	"bigserial":   "bigint",
+}
+
+// ParseSQLTables parses a DDL script with pg_query and builds the Table/Column model
+// of every CREATE TABLE statement it contains.
+//
+// Both generated scripts and database dumps (pg_dump --schema-only) are supported: constraints
+// added afterwards with ALTER TABLE are applied to their table, the "public" schema is omitted
+// from names and integer columns defaulting to a sequence are read as serial columns.
+// Other statements are ignored.
+func ParseSQLTables(sql string) ([]Table, error) {
+	tree, err := pg_query.Parse(sql)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse SQL: %v", err)
+	}
+
+	var tables []Table
+
+	for _, rawStmt := range tree.Stmts {
+		if createStmt := rawStmt.Stmt.GetCreateStmt(); createStmt != nil {
+			table, err := buildTableFromCreateStmt(createStmt)
+			if err != nil {
+				return nil, err
+			}
+			tables = append(tables, table)
+		} else if alterTableStmt := rawStmt.Stmt.GetAlterTableStmt(); alterTableStmt != nil {
+			table := findTable(tables, relationName(alterTableStmt.Relation))
+			if table == nil {
+				continue
+			}
+			if err := table.applyAlterTableCmds(alterTableStmt.Cmds); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for i := range tables {
+		tables[i].normalizeParsedColumns()
+	}
+
+	return tables, nil
+}
+
+func buildTableFromCreateStmt(stmt *pg_query.CreateStmt) (Table, error) {
+	table := Table{
+		Name: stmt.Relation.Relname,
+	}
+	if stmt.Relation.Schemaname != "public" {
+		table.Schema = stmt.Relation.Schemaname
+	}
+
+	// Table constraints are applied once every column is known
+	var tableConstraints []*pg_query.Constraint
+
+	for _, element := range stmt.TableElts {
+		if columnDef := element.GetColumnDef(); columnDef != nil {
+			column, err := buildColumnFromColumnDef(columnDef)
+			if err != nil {
+				return Table{}, fmt.Errorf("table %s: %v", table.Name, err)
+			}
+			table.ColumnDefinition = append(table.ColumnDefinition, column)
+		} else if constraint := element.GetConstraint(); constraint != nil {
+			tableConstraints = append(tableConstraints, constraint)
+		}
+	}
+
+	for _, constraint := range tableConstraints {
+		if err := table.applyTableConstraint(constraint); err != nil {
+			return Table{}, fmt.Errorf("table %s: %v", table.Name, err)
+		}
+	}
+
+	return table, nil
+}
+
+func buildColumnFromColumnDef(columnDef *pg_query.ColumnDef) (Column, error) {
+	column := Column{
+		Name:    columnDef.Colname,
+		SQLType: formatTypeName(columnDef.TypeName),
+	}
+
+	for _, node := range columnDef.Constraints {
+		constraint := node.GetConstraint()
+		if constraint == nil {
+			continue
+		}
+
+		switch constraint.Contype {
+		case pg_query.ConstrType_CONSTR_NOTNULL:
+			column.NotNull = true
+		case pg_query.ConstrType_CONSTR_PRIMARY:
+			column.PrimaryKey = true
+			column.setConstraintName("primary key", constraint.Conname)
+		case pg_query.ConstrType_CONSTR_UNIQUE:
+			column.Unique = true
+			column.setConstraintName("unique", constraint.Conname)
+		case pg_query.ConstrType_CONSTR_FOREIGN:
+			column.ForeignKey = relationReference(constraint.Pktable)
+			column.setConstraintName("references", constraint.Conname)
+		case pg_query.ConstrType_CONSTR_GENERATED:
+			expression, err := deparseExpression(constraint.RawExpr)
+			if err != nil {
+				return Column{}, fmt.Errorf("column %s: %v", column.Name, err)
+			}
+			column.Generated = expression
+		case pg_query.ConstrType_CONSTR_IDENTITY:
+			column.Identity = identityKind(constraint.GeneratedWhen)
+		case pg_query.ConstrType_CONSTR_DEFAULT:
+			expression, err := deparseDefaultExpression(constraint.RawExpr)
+			if err != nil {
+				return Column{}, fmt.Errorf("column %s: %v", column.Name, err)
+			}
+			column.DefaultValue = expression
+		case pg_query.ConstrType_CONSTR_CHECK:
+			expression, err := deparseExpression(constraint.RawExpr)
+			if err != nil {
+				return Column{}, fmt.Errorf("column %s: %v", column.Name, err)
+			}
+			column.Constraints = append(column.Constraints, CheckConstraint{Expression: expression, Name: constraint.Conname})
+		}
+	}
+
+	return column, nil
+}
+
+func (t *Table) applyTableConstraint(constraint *pg_query.Constraint) error {
+	switch constraint.Contype {
+	case pg_query.ConstrType_CONSTR_PRIMARY:
+		for _, key := range constraint.Keys {
+			if column := t.column(key.GetString_().Sval); column != nil {
+				column.PrimaryKey = true
+				column.setConstraintName("primary key", constraint.Conname)
+			}
+		}
+	case pg_query.ConstrType_CONSTR_UNIQUE:
+		// Only single column unique constraints can be expressed on a column
+		if len(constraint.Keys) == 1 {
+			if column := t.column(constraint.Keys[0].GetString_().Sval); column != nil {
+				column.Unique = true
+				column.setConstraintName("unique", constraint.Conname)
+			}
+		}
+	case pg_query.ConstrType_CONSTR_FOREIGN:
+		if len(constraint.FkAttrs) == 1 {
+			if column := t.column(constraint.FkAttrs[0].GetString_().Sval); column != nil {
+				column.ForeignKey = relationReference(constraint.Pktable)
+				column.setConstraintName("references", constraint.Conname)
+			}
+		}
+	case pg_query.ConstrType_CONSTR_CHECK:
+		expression, err := deparseExpression(constraint.RawExpr)
+		if err != nil {
+			return err
+		}
+
+		// Dumps write column checks as table constraints, keep them on their column
+		columnNames := columnReferences(constraint.RawExpr)
+		if len(columnNames) == 1 && t.column(columnNames[0]) != nil {
+			column := t.column(columnNames[0])
+			column.Constraints = append(column.Constraints, CheckConstraint{Expression: expression, Name: constraint.Conname})
+		} else {
+			t.CheckConstraints = append(t.CheckConstraints, expression)
+			if constraint.Conname != "" {
+				if t.checkNames == nil {
+					t.checkNames = map[string]string{}
+				}
+				t.checkNames[expression] = constraint.Conname
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *Table) applyAlterTableCmds(cmds []*pg_query.Node) error {
+	for _, node := range cmds {
+		cmd := node.GetAlterTableCmd()
+		if cmd == nil {
+			continue
+		}
+
+		switch cmd.Subtype {
+		case pg_query.AlterTableType_AT_AddConstraint:
+			if err := t.applyTableConstraint(cmd.Def.GetConstraint()); err != nil {
+				return fmt.Errorf("table %s: %v", t.Name, err)
+			}
+		case pg_query.AlterTableType_AT_ColumnDefault:
+			column := t.column(cmd.Name)
+			if column == nil || cmd.Def == nil {
+				continue
+			}
+			expression, err := deparseDefaultExpression(cmd.Def)
+			if err != nil {
+				return fmt.Errorf("table %s: %v", t.Name, err)
+			}
+			column.DefaultValue = expression
+		case pg_query.AlterTableType_AT_SetNotNull:
+			if column := t.column(cmd.Name); column != nil {
+				column.NotNull = true
+			}
+		case pg_query.AlterTableType_AT_AddIdentity:
+			if column := t.column(cmd.Name); column != nil && cmd.Def.GetConstraint() != nil {
+				column.Identity = identityKind(cmd.Def.GetConstraint().GeneratedWhen)
+			}
+		}
+	}
+
+	return nil
+}
+
+// normalizeParsedColumns gives the same representation to equivalent column definitions
+func (t *Table) normalizeParsedColumns() {
+	for i := range t.ColumnDefinition {
+		column := &t.ColumnDefinition[i]
+
+		// Primary keys and identity columns are implicitly not null
+		if column.PrimaryKey || column.Identity != "" {
+			column.NotNull = true
+		}
+
+		// An integer defaulting to a sequence is what a serial type expands to
+		if strings.HasPrefix(column.DefaultValue, "nextval(") {
+			for serialType, integerType := range serialTypes {
+				if column.SQLType == integerType {
+					column.SQLType = serialType
+					column.DefaultValue = ""
+					column.NotNull = true
+				}
+			}
+		}
+	}
+}
+
+// setConstraintName records the name of a constraint on the column, when it has one
+func (c *Column) setConstraintName(property string, name string) {
+	if name == "" {
+		return
+	}
+	if c.constraintNames == nil {
+		c.constraintNames = map[string]string{}
+	}
+	c.constraintNames[property] = name
+}
+
+// identityKind returns the kind of an identity column from its generated_when flag
+func identityKind(generatedWhen string) string {
+	if generatedWhen == "a" {
+		return "ALWAYS"
+	}
+	return "BY DEFAULT"
+}
+
+func (t *Table) column(name string) *Column {
+	for i := range t.ColumnDefinition {
+		if t.ColumnDefinition[i].Name == name {
+			return &t.ColumnDefinition[i]
+		}
+	}
+	return nil
+}
+
+// relationReference returns the table of a relation, with its schema unless it is in "public"
+func relationReference(relation *pg_query.RangeVar) TableReference {
+	if relation.Schemaname == "public" {
+		return TableReference{Name: relation.Relname}
+	}
+	return TableReference{Schema: relation.Schemaname, Name: relation.Relname}
+}
+
+// relationName returns the name of a table, qualified by its schema unless it is in "public"
+func relationName(relation *pg_query.RangeVar) string {
+	return relationReference(relation).String()
+}
+
+// formatTypeName renders a parsed type the way it is written in DDL (e.g. pg_catalog.int4 -> integer)
+func formatTypeName(typeName *pg_query.TypeName) string {
+	var names []string
+	for _, name := range typeName.Names {
+		names = append(names, name.GetString_().Sval)
+	}
+
+	if len(names) == 2 && names[0] == "pg_catalog" {
+		if name, ok := pgCatalogTypeNames[names[1]]; ok {
+			names = []string{name}
+		}
+	} else if len(names) == 2 && names[0] == "public" {
+		names = names[1:]
+	}
+
+	result := strings.Join(names, ".")
+
+	if len(typeName.Typmods) > 0 {
+		var typmods []string
+		for _, typmod := range typeName.Typmods {
+			// Typmods are numbers, or names like the geometry type of geometry(Point,4326)
+			if columnRef := typmod.GetColumnRef(); columnRef != nil && len(columnRef.Fields) > 0 {
+				typmods = append(typmods, columnRef.Fields[0].GetString_().Sval)
+			} else {
+				typmods = append(typmods, fmt.Sprint(typmod.GetAConst().GetIval().GetIval()))
+			}
+		}
+		result += "(" + strings.Join(typmods, ",") + ")"
+	}
+
+	for range typeName.ArrayBounds {
+		result += "[]"
+	}
+
+	return result
+}
+
+// deparseExpression turns an expression node back into SQL by deparsing it as a SELECT target
+func deparseExpression(expression *pg_query.Node) (string, error) {
+	tree := &pg_query.ParseResult{
+		Stmts: []*pg_query.RawStmt{{
+			Stmt: &pg_query.Node{
+				Node: &pg_query.Node_SelectStmt{
+					SelectStmt: &pg_query.SelectStmt{
+						TargetList: []*pg_query.Node{pg_query.MakeResTargetNodeWithVal(expression, 0)},
+					},
+				},
+			},
+		}},
+	}
+
+	sql, err := pg_query.Deparse(tree)
+	if err != nil {
+		return "", fmt.Errorf("cannot deparse expression: %v", err)
+	}
+
+	return strings.TrimPrefix(sql, "SELECT "), nil
+}
+
+// deparseDefaultExpression deparses a DEFAULT expression, dropping the cast that dumps add
+// to constants (e.g. 'anonymous'::text)
+func deparseDefaultExpression(expression *pg_query.Node) (string, error) {
+	if typeCast := expression.GetTypeCast(); typeCast != nil && typeCast.Arg.GetAConst() != nil {
+		expression = typeCast.Arg
+	}
+	return deparseExpression(expression)
+}
+
+// columnReferences lists the distinct column names used in an expression
+func columnReferences(expression *pg_query.Node) []string {
+	var names []string
+
+	var visit func(message protoreflect.Message)
+	visit = func(message protoreflect.Message) {
+		if columnRef, ok := message.Interface().(*pg_query.ColumnRef); ok {
+			fields := columnRef.Fields
+			if len(fields) > 0 {
+				name := fields[len(fields)-1].GetString_().GetSval()
+				if name != "" && !slices.Contains(names, name) {
+					names = append(names, name)
+				}
+			}
+			return
+		}
+
+		message.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+			if field.Kind() != protoreflect.MessageKind {
+				return true
+			}
+			if field.IsList() {
+				for i := 0; i < value.List().Len(); i++ {
+					visit(value.List().Get(i).Message())
+				}
+			} else if !field.IsMap() {
+				visit(value.Message())
+			}
+			return true
+		})
+	}
+
+	visit(expression.ProtoReflect())
+	return names
+}
@@ -5,8 +5,8 @@ import (
 	"slices"
 	"strings"
 
-	"github.com/jinzhu/inflection"
 	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/orderedmap"
 )
 
 var postgresReservedWords = []string{
@@ -28,7 +28,15 @@ var postgresReservedWords = []string{
 type Table struct {
 	DefaultDatabaseName string
 	Name                string
+	Schema              string // PostgreSQL schema of the table (x-schema), the search path when empty
+	Tablespace          string // Tablespace of the table (x-tablespace)
+	FullTextLanguage    string // Text search configuration of the search_vector column (x-fulltext), if any
+	Comment             string // COMMENT ON TABLE, from the title and description of the schema
 	ColumnDefinition    []Column
+	CheckConstraints    []string
+	Inherits            []TableReference  // Parent tables of a PostgreSQL table inheritance
+	ChildTables         []Table           // Tables storing nested objects of this table, created after it
+	checkNames          map[string]string // Names of the check constraints read back from SQL, by expression
 }
 
 func GenerateEnumSQL(enumName string, values []string) (string, error) {
@@ -39,7 +47,7 @@ func GenerateEnumSQL(enumName string, values []string) (string, error) {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("CREATE TYPE %s AS ENUM (", enumName))
 	for i, value := range values {
-		sb.WriteString(fmt.Sprintf("'%s'", value))
+		sb.WriteString(quoteLiteral(value))
 		if i < len(values)-1 {
 			sb.WriteString(", ")
 		}
@@ -53,8 +61,7 @@ func (t Table) createEnumSQLStatement() (string, error) {
 
 	for _, column := range t.ColumnDefinition {
 		if len(column.Enum) > 0 {
-			enumName := fmt.Sprintf("%s_%s", inflection.Singular(t.Name), column.Name)
-			enumSQL, err := GenerateEnumSQL(enumName, column.Enum)
+			enumSQL, err := GenerateEnumSQL(column.customType, column.Enum)
 			if err != nil {
 				return "", err // Handle the error appropriately, possibly accumulating errors or stopping at the first.
 			}
@@ -69,6 +76,51 @@ func isReservedWord(word string) bool {
 	return slices.Contains(postgresReservedWords, strings.ToUpper(word))
 }
 
+// quoteIdentifier double quotes a table or column name when it is a reserved word
+func quoteIdentifier(name string) string {
+	if isReservedWord(name) {
+		return fmt.Sprintf("\"%s\"", name)
+	}
+	return name
+}
+
+// TableReference names a table from another one, e.g. the table referenced by a foreign key
+type TableReference struct {
+	Schema string // PostgreSQL schema of the table, the search path when empty
+	Name   string
+}
+
+// String returns the name of the table, prefixed with its schema when it has one
+func (r TableReference) String() string {
+	if r.Schema == "" {
+		return r.Name
+	}
+	return r.Schema + "." + r.Name
+}
+
+// sqlName returns the qualified name of the table, with the reserved words quoted
+func (r TableReference) sqlName() string {
+	if r.Schema == "" {
+		return quoteIdentifier(r.Name)
+	}
+	return quoteIdentifier(r.Schema) + "." + quoteIdentifier(r.Name)
+}
+
+// reference returns the name of the table, as referenced from another table
+func (t Table) reference() TableReference {
+	return TableReference{Schema: t.Schema, Name: t.Name}
+}
+
+// qualifiedName returns the name of the table, prefixed with its schema when it has one
+func (t Table) qualifiedName() string {
+	return t.reference().String()
+}
+
+// sqlName returns the qualified name of the table, with the reserved words quoted
+func (t Table) sqlName() string {
+	return t.reference().sqlName()
+}
+
 func (t Table) CreateSQLStatement() (string, error) {
 	var sb strings.Builder
 
@@ -82,11 +134,7 @@ func (t Table) CreateSQLStatement() (string, error) {
 	sb.WriteString("CREATE TABLE IF NOT EXISTS ")
 
 	// Handle reserved words
-	if isReservedWord(t.Name) {
-		sb.WriteString(fmt.Sprintf("\"%s\"", t.Name))
-	} else {
-		sb.WriteString(t.Name)
-	}
+	sb.WriteString(t.sqlName())
 
 	sb.WriteString(" (\n")
 
@@ -102,70 +150,188 @@ func (t Table) CreateSQLStatement() (string, error) {
 		}
 	}
 
-	sb.WriteString("\n);")
+	for _, check := range t.CheckConstraints {
+		sb.WriteString(fmt.Sprintf(",\nCHECK (%s)", check))
+	}
 
-	return sb.String(), nil
+	sb.WriteString("\n)")
 
-}
+	if len(t.Inherits) > 0 {
+		var parentTables []string
+		for _, parentTable := range t.Inherits {
+			parentTables = append(parentTables, parentTable.sqlName())
+		}
+		sb.WriteString(fmt.Sprintf(" INHERITS (%s)", strings.Join(parentTables, ", ")))
+	}
 
-func toSnakeCase(s string) string {
-	var result string
+	if t.Tablespace != "" {
+		sb.WriteString(fmt.Sprintf(" TABLESPACE %s", quoteIdentifier(t.Tablespace)))
+	}
+
+	sb.WriteString(";")
 
-	for i, v := range s {
-		if i > 0 && v >= 'A' && v <= 'Z' {
-			result += "_"
+	// Add GIN indexes
+	for _, column := range t.ColumnDefinition {
+		if !column.GinIndex {
+			continue
+		}
+		if !strings.EqualFold(column.SQLType, "JSONB") && !strings.EqualFold(column.SQLType, "TSVECTOR") {
+			return "", fmt.Errorf("x-searchable requires %s.%s to be a JSONB column", t.Name, column.Name)
 		}
+		sb.WriteString(fmt.Sprintf("\n\nCREATE INDEX IF NOT EXISTS %s_%s_idx ON %s USING GIN (%s);", t.Name, column.Name, t.sqlName(), column.Name))
+	}
 
-		result += string(v)
+	// Add the triggers setting the onUpdate audit columns, like updated_at
+	for _, column := range t.ColumnDefinition {
+		if column.setOnUpdate {
+			sb.WriteString(fmt.Sprintf("\n\nCREATE OR REPLACE TRIGGER %s_set_%s BEFORE UPDATE ON %s FOR EACH ROW EXECUTE FUNCTION set_%s();", t.Name, column.Name, t.sqlName(), column.Name))
+		}
 	}
 
-	return strings.ToLower(result)
+	// Document the table and its columns
+	sb.WriteString(t.commentStatements())
+
+	return sb.String(), nil
+
+}
+
+// isDatabaseEntity tells if a component schema is stored in a table of its own
+func isDatabaseEntity(schema *highbase.Schema) bool {
+	if schema == nil {
+		return false
+	}
+	if val, ok := extensionValue(schema, "x-database-entity"); ok && val == "false" {
+		return false
+	}
+	// Spatial objects and ranges are values stored in a column
+	if isGeoJSONSchema(schema) || isRangeSchema(schema) {
+		return false
+	}
+	return schema.Properties != nil || schema.AllOf != nil || discriminatedVariants(schema) != nil
 }
 
-func BuildTableFromSchema(tableName string, schema *highbase.Schema) *Table {
+// tableFromSchema returns the table storing a component schema, without columns. Its name is x-table-name
+// or the plural of the component name, and it is placed in the x-schema schema and the x-tablespace tablespace.
+func tableFromSchema(schemaName string, schema *highbase.Schema, options Options) Table {
 	table := Table{
-		Name: inflection.Plural(toSnakeCase(tableName)),
+		Name: options.naming().TableName(schemaName),
+	}
+	if name, ok := extensionValue(schema, "x-table-name"); ok {
+		table.Name = name
 	}
+	table.Schema, _ = extensionValue(schema, "x-schema")
+	table.Tablespace, _ = extensionValue(schema, "x-tablespace")
+	if schema != nil {
+		table.Comment = schemaComment(schema.Title, schema.Description)
+	}
+	return table
+}
 
-	properties := schema.Properties
-	if properties == nil && schema.AllOf == nil {
-		fmt.Printf("No properties found for schema: %s\n", tableName)
-		return &table
+// referencedEntity returns the table storing a referenced component schema, and the schema of this table.
+// The variants of a discriminated oneOf / anyOf are stored in the table of their parent.
+func referencedEntity(ref string, schema *highbase.Schema, options Options) (TableReference, *highbase.Schema) {
+	name := schemaNameFromReference(ref)
+	if parent, ok := options.singleTableParents[name]; ok {
+		name, schema = parent.name, parent.schema
 	}
+	return tableFromSchema(name, schema, options).reference(), schema
+}
+
+func BuildTableFromSchema(tableName string, schema *highbase.Schema, options Options) (*Table, error) {
+	table := tableFromSchema(tableName, schema, options)
 
 	// Check if there is a custom extension x-database-entity
-	if schema.Extensions != nil {
-		if val, ok := schema.Extensions.Get("x-database-entity"); ok && val.Value == "false" {
-			return &table
+	if val, ok := extensionValue(schema, "x-database-entity"); ok && val == "false" {
+		return &table, nil
+	}
+
+	// Discriminated oneOf / anyOf are stored in a single table
+	if discriminatedVariants(schema) != nil {
+		if err := buildSingleTableInheritance(&table, tableName, schema, options); err != nil {
+			return nil, fmt.Errorf("error building the table %s: %v", tableName, err)
 		}
+		return &table, nil
+	}
+
+	// Shared enums, primitives, composite types, spatial objects and ranges are stored with the columns referencing them
+	if isStringEnum(schema) || isDomainSchema(schema) || isCompositeSchema(schema) || isGeoJSONSchema(schema) || isRangeSchema(schema) {
+		return &table, nil
+	}
+
+	properties := schema.Properties
+	if properties == nil && schema.AllOf == nil {
+		fmt.Printf("No properties found for schema: %s\n", tableName)
+		return &table, nil
 	}
 
 	requiredColumns := schema.Required
 
 	// Check if there is allOf in the schema
-	if schema.AllOf != nil {
+	strategy, err := inheritanceStrategy(schema)
+	if err != nil {
+		return nil, fmt.Errorf("error building the table %s: %v", tableName, err)
+	}
+
+	if schema.AllOf != nil && strategy != InheritanceFlatten {
+		if err := buildAllOfInheritance(&table, tableName, schema, strategy, options); err != nil {
+			return nil, fmt.Errorf("error building the table %s: %v", tableName, err)
+		}
+	} else if schema.AllOf != nil {
 		for _, item := range schema.AllOf {
 			requiredColumns = append(requiredColumns, item.Schema().Required...)
-			colDef, err := BuildColumnsFromSchema(tableName, *item.Schema().Properties, requiredColumns)
+			colDef, err := BuildColumnsFromSchema(tableName, *item.Schema().Properties, requiredColumns, options)
 			if err != nil {
-				fmt.Printf("Error building columns from schema: %v\n", err)
-				return &table
+				return nil, fmt.Errorf("error building the table %s: %v", tableName, err)
 			}
 
 			table.ColumnDefinition = append(table.ColumnDefinition, colDef...)
 		}
 	} else {
-		colDef, err := BuildColumnsFromSchema(tableName, *properties, requiredColumns)
+		colDef, err := BuildColumnsFromSchema(tableName, *properties, requiredColumns, options)
 		if err != nil {
-			fmt.Printf("Error building columns from schema: %v\n", err)
-			return &table
+			return nil, fmt.Errorf("error building the table %s: %v", tableName, err)
 		}
 		table.ColumnDefinition = colDef
 	}
 
-	return &table
+	// Full-text search on the x-fulltext properties
+	if err := table.addFullTextSearch(schema, options); err != nil {
+		return nil, fmt.Errorf("error building the table %s: %v", tableName, err)
+	}
+
+	// Nested objects stored in their own table
+	nestedProperties := []*orderedmap.Map[string, *highbase.SchemaProxy]{schema.Properties}
+	for _, item := range schema.AllOf {
+		if item.GetReference() == "" && item.Schema() != nil {
+			nestedProperties = append(nestedProperties, item.Schema().Properties)
+		}
+	}
+	for _, properties := range nestedProperties {
+		if err := table.buildNestedTables(properties, options); err != nil {
+			return nil, fmt.Errorf("error building the nested tables of %s: %v", tableName, err)
+		}
+	}
+
+	return &table, nil
+}
+
+// Prerequisites returns the statements to run before creating the table, e.g. helper functions
+// used by its constraints. The same statement can be required by several tables.
+func (t Table) Prerequisites() []string {
+	var prerequisites []string
+	if t.Schema != "" {
+		prerequisites = append(prerequisites, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s;", quoteIdentifier(t.Schema)))
+	}
+	for _, column := range t.ColumnDefinition {
+		for _, prerequisite := range column.prerequisites {
+			if !slices.Contains(prerequisites, prerequisite) {
+				prerequisites = append(prerequisites, prerequisite)
+			}
+		}
+	}
+	return prerequisites
 }
 
 func (t Table) DeleteSQLStatement() string {
-	return fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE;\n", t.Name)
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE;\n", t.sqlName())
 }
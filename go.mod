@@ -8,6 +8,8 @@ require (
 	github.com/jinzhu/inflection v1.0.0
 	github.com/pb33f/libopenapi v0.17.0
 	github.com/pganalyze/pg_query_go/v5 v5.1.0
+	google.golang.org/protobuf v1.34.2
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -18,6 +20,4 @@ require (
 	github.com/vmware-labs/yaml-jsonpath v0.3.2 // indirect
 	github.com/wk8/go-ordered-map/v2 v2.1.9-0.20240815153524-6ea36470d1bd // indirect
 	golang.org/x/net v0.28.0 // indirect
-	google.golang.org/protobuf v1.34.2 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -6,7 +6,9 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/oliviernguyenquoc/oapisqlc/dbSchema"
 	"github.com/pb33f/libopenapi"
@@ -55,23 +57,43 @@ func parseOpenAPISpec(openAPISpec []byte) (*v3.Document, error) {
 	return &v3Model.Model, nil
 }
 
-// fromComponentsToSQL takes a parsed OpenAPI document and generates a SQL statement.
-func fromComponentsToSQL(doc *v3.Components, flags Flags) (string, error) {
-
+// buildTables builds the tables of the component schemas, followed by their child tables
+func buildTables(doc *v3.Components, flags Flags) ([]dbSchema.Table, error) {
 	schemas := doc.Schemas
 
 	var tableDefinitions []dbSchema.Table
 
+	// Variants of a discriminated oneOf / anyOf are stored in the table of their parent
+	var singleTableVariants []string
+	for schema := schemas.First(); schema != nil; schema = schema.Next() {
+		singleTableVariants = append(singleTableVariants, dbSchema.SingleTableVariants(schema.Value().Schema())...)
+	}
+	options := flags.options.WithSingleTableVariants(schemas)
+
 	for schema := schemas.First(); schema != nil; schema = schema.Next() {
 		tableName := schema.Key()
-		table := dbSchema.BuildTableFromSchema(tableName, schema.Value().Schema())
+		if slices.Contains(singleTableVariants, tableName) {
+			continue
+		}
+
+		table, err := dbSchema.BuildTableFromSchema(tableName, schema.Value().Schema(), options)
+		if err != nil {
+			return nil, err
+		}
 
 		// If there is no column, no need to create a table
 		if len(table.ColumnDefinition) != 0 {
 			tableDefinitions = append(tableDefinitions, *table)
+			tableDefinitions = append(tableDefinitions, table.ChildTables...)
 		}
 	}
 
+	return tableDefinitions, nil
+}
+
+// fromComponentsToSQL takes the tables built from a parsed OpenAPI document and generates a SQL statement.
+func fromComponentsToSQL(tableDefinitions []dbSchema.Table, flags Flags) (string, error) {
+
 	var query string
 
 	// Add delete statements at the beginning of the output file
@@ -82,6 +104,18 @@ func fromComponentsToSQL(doc *v3.Components, flags Flags) (string, error) {
 		}
 	}
 
+	// Add the statements the tables depend on, once
+	var prerequisites []string
+	for _, table := range tableDefinitions {
+		for _, prerequisite := range table.Prerequisites() {
+			if !slices.Contains(prerequisites, prerequisite) {
+				prerequisites = append(prerequisites, prerequisite)
+				query += "\n\n"
+				query += prerequisite
+			}
+		}
+	}
+
 	for _, table := range tableDefinitions {
 		statement, err := table.CreateSQLStatement()
 		if err != nil {
@@ -91,14 +125,16 @@ func fromComponentsToSQL(doc *v3.Components, flags Flags) (string, error) {
 		query += statement
 	}
 
-	normalizedQuery, err := pg_query.Normalize(query)
+	// Check the query is valid. It is not normalized, as normalizing replaces the constants
+	// of function bodies with parameters.
+	_, err := pg_query.Parse(query)
 	if err != nil {
-		slog.Error("Error checking and normalizing query %s", query, err)
+		slog.Error("Error checking query %s", query, err)
 		return "", err
 	}
 
 	// Placeholder SQL generation logic
-	return normalizedQuery, nil
+	return query, nil
 }
 
 func fromComponentPathToSQL(doc *v3.Paths, flags Flags) ([]string, error) {
@@ -139,6 +175,20 @@ func fromComponentPathToSQL(doc *v3.Paths, flags Flags) ([]string, error) {
 	return pathSQLStatements, nil
 }
 
+// fromComponentsToQueries returns the INSERT and UPDATE queries of the tables, and the search queries
+// of the tables with an x-fulltext search
+func fromComponentsToQueries(tableDefinitions []dbSchema.Table) []string {
+	var queries []string
+	for _, table := range tableDefinitions {
+		for _, query := range []string{table.InsertQuery(), table.UpdateQuery(), table.SearchQuery()} {
+			if query != "" {
+				queries = append(queries, query)
+			}
+		}
+	}
+	return queries
+}
+
 func writeInFolder(sqlStatement string, flags Flags) error {
 	// Create folder if not exist
 	err := os.MkdirAll(flags.outputFolderPath, 0755)
@@ -157,27 +207,69 @@ func writeInFolder(sqlStatement string, flags Flags) error {
 	return nil
 }
 
+// writeQueriesInFolder writes the generated queries next to the schema, when there are any
+func writeQueriesInFolder(queries []string, flags Flags) error {
+	if len(queries) == 0 {
+		return nil
+	}
+
+	err := os.WriteFile(filepath.Join(flags.outputFolderPath, "queries.sql"), []byte(strings.Join(queries, "\n\n")+"\n"), 0644)
+	if err != nil {
+		fmt.Printf("Failed to write queries to file: %v\n", err)
+		return err
+	}
+
+	return nil
+}
+
+// loadOptions loads the configuration file, if any
+func loadOptions(configPath string) (dbSchema.Options, error) {
+	if configPath == "" {
+		return dbSchema.Options{}, nil
+	}
+	return dbSchema.LoadOptions(configPath)
+}
+
 // flags
 type Flags struct {
 	deleteStatements bool
 	outputFolderPath string
+	options          dbSchema.Options
 }
 
 func main() {
 	if len(os.Args) < 2 {
-		fmt.Println("Usage: go run main.go <path_to_yaml_file>")
+		fmt.Println("Usage: go run main.go [--config <path_to_config_file>] <path_to_yaml_file>")
+		fmt.Println("       go run main.go check --against <path_to_sql_file> <path_to_yaml_file>")
+		fmt.Println("       go run main.go compare --dump <path_to_sql_dump> [--alterStatements] <path_to_yaml_file>")
 		os.Exit(1)
 	}
 
-	filePath := os.Args[1]
+	switch os.Args[1] {
+	case "check":
+		os.Exit(runCheck(os.Args[2:]))
+	case "compare":
+		os.Exit(runCompare(os.Args[2:]))
+	}
 
 	// Parse flags
 	deleteStatements := flag.Bool("deleteStatements", false, "Add delete statements to SQL output")
 	outputFolderPath := flag.String("outputFolder", "", "Path to output folder")
+	configPath := flag.String("config", "", "Path to a YAML configuration file")
+	flag.Parse()
+
+	filePath := flag.Arg(0)
+
+	options, err := loadOptions(*configPath)
+	if err != nil {
+		fmt.Printf("Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
 
 	flags := Flags{
 		deleteStatements: *deleteStatements,
 		outputFolderPath: *outputFolderPath,
+		options:          options,
 	}
 
 	// load an OpenAPI 3.1 specification from bytes
@@ -195,7 +287,13 @@ func main() {
 	}
 
 	// Generate SQL statement based on the OpenAPI spec
-	DDLSQLStatement, err := fromComponentsToSQL(doc.Components, flags)
+	tableDefinitions, err := buildTables(doc.Components, flags)
+	if err != nil {
+		fmt.Printf("Failed to generate SQL: %v\n", err)
+		os.Exit(1)
+	}
+
+	DDLSQLStatement, err := fromComponentsToSQL(tableDefinitions, flags)
 	if err != nil {
 		fmt.Printf("Failed to generate SQL: %v\n", err)
 		os.Exit(1)
@@ -206,12 +304,16 @@ func main() {
 		fmt.Printf("Failed to generate SQL: %v\n", err)
 		os.Exit(1)
 	}
+	PathSQLStatements = append(PathSQLStatements, fromComponentsToQueries(tableDefinitions)...)
 
 	if flags.outputFolderPath != "" {
 		err := writeInFolder(DDLSQLStatement, flags)
 		if err != nil {
 			os.Exit(1)
 		}
+		if err := writeQueriesInFolder(PathSQLStatements, flags); err != nil {
+			os.Exit(1)
+		}
 	} else {
 		fmt.Println("Generated SQL Statement:", DDLSQLStatement)
 		fmt.Print("\n\n")
@@ -2,53 +2,73 @@ package main
 
 import (
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/oliviernguyenquoc/oapisqlc/dbSchema"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 	pg_query "github.com/pganalyze/pg_query_go/v5"
 )
 
-// Comparison based on fingerprinting
+// Comparison of the deparsed statements: the formatting is ignored, but unlike fingerprints,
+// constants and type modifiers are compared
 func compareSQL(t *testing.T, expectedSQL, actualSQL string) {
+	t.Helper()
 
-	expectedFingerprint, err := pg_query.Fingerprint(expectedSQL)
+	expected, err := deparseSQL(expectedSQL)
 	if err != nil {
 		t.Errorf("Error parsing expected SQL: %v", err)
 	}
 
-	actualFingerprint, err := pg_query.Fingerprint(actualSQL)
+	actual, err := deparseSQL(actualSQL)
 	if err != nil {
 		t.Errorf("Error parsing actual SQL: %v", err)
 	}
 
-	if expectedFingerprint != actualFingerprint {
+	if expected != actual {
 		t.Errorf(`
-		Expected SQL did not match (at least, they do not have the samefingerprinting). 
+		Expected SQL did not match.
 		Got: %v
 		
 		Wanted: %v
 		`,
-			actualSQL, expectedSQL)
+			actual, expected)
 	}
 }
 
 func testOpenAPISpecToSQL(t *testing.T, filename, expectedSQL string, flags Flags) {
 
+	sql, err := generateSQL(t, filename, flags)
+	if err != nil {
+		t.Errorf("Error transforming OpenAPI to SQL: %v", err)
+	}
+	compareSQL(t, expectedSQL, sql)
+}
+
+// parseTestSpec reads and parses an OpenAPI spec of the test data
+func parseTestSpec(t *testing.T, filename string) *v3.Document {
+	t.Helper()
+
 	apiSpec, err := os.ReadFile(filename)
 	if err != nil {
-		t.Errorf("Error reading OpenAPI spec: %v", err)
+		t.Fatalf("Error reading OpenAPI spec: %v", err)
 	}
 
-	// Parse the OpenAPI specification
 	doc, err := parseOpenAPISpec(apiSpec)
 	if err != nil {
-		t.Errorf("Error parsing OpenAPI spec: %v", err)
+		t.Fatalf("Error parsing OpenAPI spec: %v", err)
 	}
+	return doc
+}
 
-	sql, err := fromComponentsToSQL(doc.Components, flags)
+// generateSQL generates the SQL of an OpenAPI spec of the test data
+func generateSQL(t *testing.T, filename string, flags Flags) (string, error) {
+	t.Helper()
+	tableDefinitions, err := buildTables(parseTestSpec(t, filename).Components, flags)
 	if err != nil {
-		t.Errorf("Error transforming OpenAPI to SQL: %v", err)
+		return "", err
 	}
-	compareSQL(t, expectedSQL, sql)
+	return fromComponentsToSQL(tableDefinitions, flags)
 }
 
 func testErrors(t *testing.T, filename string) {
@@ -67,11 +87,27 @@ func testErrors(t *testing.T, filename string) {
 	}
 }
 
+// testSchemaErrors checks that every component schema of the spec is rejected, and so is the spec
+func testSchemaErrors(t *testing.T, filename string) {
+	t.Helper()
+
+	schemas := parseTestSpec(t, filename).Components.Schemas
+	for schema := schemas.First(); schema != nil; schema = schema.Next() {
+		if _, err := dbSchema.BuildTableFromSchema(schema.Key(), schema.Value().Schema(), dbSchema.Options{}); err == nil {
+			t.Errorf("Expected an error building the table of %s", schema.Key())
+		}
+	}
+
+	if sql, err := generateSQL(t, filename, Flags{}); err == nil {
+		t.Errorf("Expected an error, got:\n%s", sql)
+	}
+}
+
 func TestSimpleSchemaTransformation(t *testing.T) {
 
 	testOpenAPISpecToSQL(t, "tests/testdata/simple_schema.yaml", `
 	CREATE TABLE IF NOT EXISTS users (
-		id BIGSERIAL NOT NULL PRIMARY KEY,
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
 		username TEXT
 	);`, Flags{})
 }
@@ -93,11 +129,11 @@ func TestComponentReferences(t *testing.T) {
 
 	testOpenAPISpecToSQL(t, "tests/testdata/component_references.yaml", `
 	CREATE TABLE IF NOT EXISTS users (
-        id BIGSERIAL NOT NULL PRIMARY KEY,
-        address_id INTEGER REFERENCES addresses(id)
+        id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+        address_id BIGINT REFERENCES addresses(id)
     );
     CREATE TABLE IF NOT EXISTS addresses (
-        id BIGSERIAL NOT NULL PRIMARY KEY,
+        id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
         street TEXT,
         city TEXT
     );`, Flags{})
@@ -110,11 +146,11 @@ func TestComponentReferencesWithDeleteStatements(t *testing.T) {
 	DROP TABLE IF EXISTS addresses CASCADE;
 
 	CREATE TABLE IF NOT EXISTS users (
-        id BIGSERIAL NOT NULL PRIMARY KEY,
-        address_id INTEGER REFERENCES addresses(id)
+        id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+        address_id BIGINT REFERENCES addresses(id)
     );
     CREATE TABLE IF NOT EXISTS addresses (
-        id BIGSERIAL NOT NULL PRIMARY KEY,
+        id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
         street TEXT,
         city TEXT
     );`, Flags{deleteStatements: true})
@@ -134,19 +170,19 @@ func TestDataTypes(t *testing.T) {
 		fileData BYTEA,
 		dateValue DATE,
 		dateTimeValue TIMESTAMP,
-		arrayValue JSON,
-		objectValue JSON
+		arrayValue TEXT[],
+		objectValue JSONB
 	);`, Flags{})
 }
 
 func TestConstraintsTranslation(t *testing.T) {
 	testOpenAPISpecToSQL(t, "tests/testdata/constraints.yaml", `
     CREATE TABLE IF NOT EXISTS products (
-        productId INTEGER CHECK (productId >= 1.000000 AND productId <= 1000.000000),
+        productId INTEGER CHECK (productId >= 1 AND productId <= 1000),
         productName TEXT CHECK (char_length(productName) >= 1 AND char_length(productName) <= 100),
-        productPrice NUMERIC CHECK (productPrice >= 0.010000 AND productPrice <= 9999.990000),
+        productPrice NUMERIC CHECK (productPrice >= 0.01 AND productPrice <= 9999.99),
         productCode TEXT CHECK (productCode ~ '^[A-Z0-9]{10}$'),
-        releaseDate DATE DEFAULT 2023-01-01
+        releaseDate DATE DEFAULT '2023-01-01'
     );`, Flags{})
 }
 
@@ -158,8 +194,19 @@ func TestCircularReferencesParsingError(t *testing.T) {
 
 func TestCircularReferences(t *testing.T) {
 
-	// No table should be created if the references are circular and cannot be resolved.
-	testOpenAPISpecToSQL(t, "tests/testdata/circular_references.yaml", "", Flags{})
+	// Entities referencing each other are resolved to foreign keys on both sides
+	testOpenAPISpecToSQL(t, "tests/testdata/circular_references.yaml", `
+	CREATE TABLE IF NOT EXISTS ones (
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		thing_id BIGINT NOT NULL REFERENCES twos(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS twos (
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		testThing_id BIGINT REFERENCES ones(id)
+	);
+
+	COMMENT ON TABLE twos IS 'test two';`, Flags{})
 }
 
 func TestAllOfSchema(t *testing.T) {
@@ -176,36 +223,709 @@ func TestAllOfSchema(t *testing.T) {
     );`, Flags{})
 }
 
+func TestAllOfInheritanceStrategies(t *testing.T) {
+	testOpenAPISpecToSQL(t, "tests/testdata/allOf_inheritance_strategies.yaml", `
+	CREATE TABLE IF NOT EXISTS animals (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		name TEXT NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS dogs (
+		id INTEGER NOT NULL PRIMARY KEY REFERENCES animals(id),
+		breed TEXT NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS cats (
+		indoor BOOLEAN
+	) INHERITS (animals);
+	CREATE TABLE IF NOT EXISTS birds (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		name TEXT NOT NULL,
+		wingspan NUMERIC
+	);`, Flags{})
+}
+
+func TestOneOfWithDiscriminator(t *testing.T) {
+	// WalletPayment is written with allOf, and Refund references the variant CardPayment
+	testOpenAPISpecToSQL(t, "tests/testdata/oneOf_discriminator.yaml", `
+	CREATE TYPE payment_method AS ENUM ('card', 'bank_transfer', 'wallet');
+
+	CREATE TABLE IF NOT EXISTS payments (
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		amount NUMERIC NOT NULL,
+		method payment_method NOT NULL,
+		card_number TEXT,
+		expiry TEXT,
+		iban TEXT,
+		bic TEXT,
+		wallet TEXT,
+		CHECK (method <> 'card' OR (card_number IS NOT NULL AND expiry IS NOT NULL)),
+		CHECK (method <> 'bank_transfer' OR (iban IS NOT NULL)),
+		CHECK (method <> 'wallet' OR (wallet IS NOT NULL))
+	);
+
+	CREATE TABLE IF NOT EXISTS refunds (
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		payment_id BIGINT REFERENCES payments(id)
+	);
+
+	CREATE TYPE notification_channel AS ENUM ('Email', 'Sms');
+
+	CREATE TABLE IF NOT EXISTS notifications (
+		channel notification_channel NOT NULL,
+		address TEXT,
+		phone TEXT,
+		CHECK (channel <> 'Email' OR (address IS NOT NULL)),
+		CHECK (channel <> 'Sms' OR (phone IS NOT NULL))
+	);`, Flags{})
+}
+
+func TestNestedObjectStorages(t *testing.T) {
+	testOpenAPISpecToSQL(t, "tests/testdata/nested_objects.yaml", `
+	CREATE TABLE IF NOT EXISTS users (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		address_street TEXT NOT NULL,
+		address_city TEXT,
+		preferences JSONB CHECK (jsonb_typeof(preferences) = 'object' AND preferences ?& ARRAY['language'] AND (NOT preferences ? 'language' OR jsonb_typeof(preferences->'language') = 'string') AND (NOT preferences ? 'newsletter' OR jsonb_typeof(preferences->'newsletter') = 'boolean')),
+		metadata JSONB
+	);
+	CREATE TABLE IF NOT EXISTS user_billing_addresses (
+		user_id INTEGER NOT NULL PRIMARY KEY REFERENCES users(id),
+		street TEXT NOT NULL,
+		zip TEXT
+	);`, Flags{})
+}
+
+func TestCompositeTypes(t *testing.T) {
+	testOpenAPISpecToSQL(t, "tests/testdata/composite_types.yaml", `
+	CREATE TYPE money_amount AS (
+		amount NUMERIC(12,2),
+		currency TEXT
+	);
+
+	CREATE TYPE geo_point AS (
+		latitude DOUBLE PRECISION,
+		longitude DOUBLE PRECISION
+	);
+
+	CREATE TYPE refund_dimensions AS (
+		width INTEGER,
+		height INTEGER
+	);
+
+	CREATE TABLE IF NOT EXISTS orders (
+		total money_amount NOT NULL,
+		shipping money_amount,
+		destination geo_point
+	);
+
+	CREATE TABLE IF NOT EXISTS refunds (
+		amount money_amount,
+		dimensions refund_dimensions
+	);`, Flags{})
+}
+
+func TestTablePlacement(t *testing.T) {
+	testOpenAPISpecToSQL(t, "tests/testdata/table_placement.yaml", `
+	DROP TABLE IF EXISTS crm.crm_customer CASCADE;
+	DROP TABLE IF EXISTS sales.orders CASCADE;
+	DROP TABLE IF EXISTS sales.order_shippings CASCADE;
+
+	CREATE SCHEMA IF NOT EXISTS crm;
+
+	CREATE SCHEMA IF NOT EXISTS sales;
+
+	CREATE TABLE IF NOT EXISTS crm.crm_customer (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		name TEXT
+	);
+
+	CREATE TABLE IF NOT EXISTS sales.orders (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		customer_id INTEGER REFERENCES crm.crm_customer(id)
+	) TABLESPACE fast_ssd;
+
+	CREATE TABLE IF NOT EXISTS sales.order_shippings (
+		order_id INTEGER NOT NULL PRIMARY KEY REFERENCES sales.orders(id),
+		carrier TEXT
+	) TABLESPACE fast_ssd;`, Flags{deleteStatements: true})
+}
+
+func TestSpatialAndRangeTypes(t *testing.T) {
+	expectedSQL := `
+	CREATE EXTENSION IF NOT EXISTS postgis;
+
+	CREATE TABLE IF NOT EXISTS depots (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		location GEOMETRY(Point,4326) NOT NULL,
+		area GEOMETRY(Geometry,4326),
+		position geography(Point,4326),
+		opening_hours TSTZRANGE,
+		season DATERANGE
+	);
+
+	CREATE TYPE seat_type AS ENUM ('aisle', 'window');
+
+	CREATE TABLE IF NOT EXISTS seats (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		type seat_type,
+		coordinates TEXT
+	);
+
+	CREATE TABLE IF NOT EXISTS bookings (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		seat_id INTEGER REFERENCES seats(id),
+		pin JSONB
+	);`
+	// Seat and pin have a type and coordinates, but are not GeoJSON geometries
+	options := dbSchema.Options{TimestampWithTimeZone: true}
+	testOpenAPISpecToSQL(t, "tests/testdata/spatial_and_ranges.yaml", expectedSQL, Flags{options: options})
+}
+
+func TestNativeArrays(t *testing.T) {
+	testOpenAPISpecToSQL(t, "tests/testdata/native_arrays.yaml", `
+	CREATE OR REPLACE FUNCTION array_has_unique_items(items anyarray) RETURNS boolean
+	LANGUAGE sql IMMUTABLE AS $$
+		SELECT cardinality(items) = (SELECT count(DISTINCT item) FROM unnest(items) AS item)
+	$$;
+
+	CREATE TABLE IF NOT EXISTS pets (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		photoUrls TEXT[] CHECK (cardinality(photoUrls) >= 1 AND cardinality(photoUrls) <= 10),
+		scores BIGINT[],
+		tags TEXT[] CHECK (array_has_unique_items(tags)),
+		attributes JSONB
+	);`, Flags{})
+}
+
+func TestEnumArrays(t *testing.T) {
+	// Arrays of string enums are arrays of the enum type, or checked against the values with the check strategy
+	testOpenAPISpecToSQL(t, "tests/testdata/enum_arrays.yaml", `
+	CREATE TYPE status AS ENUM ('available', 'sold');
+
+	CREATE TYPE pet_colors AS ENUM ('black', 'white');
+
+	CREATE TABLE IF NOT EXISTS pets (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		statuses status[],
+		colors pet_colors[],
+		sizes TEXT[] CHECK (cardinality(sizes) <= 2 AND sizes <@ ARRAY['small', 'large'])
+	);`, Flags{})
+}
+
+func TestStringFormats(t *testing.T) {
+	testOpenAPISpecToSQL(t, "tests/testdata/string_formats.yaml", `
+	CREATE TABLE IF NOT EXISTS accounts (
+		id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+		email TEXT NOT NULL CHECK (email ~ '^[^@\s]+@[^@\s]+\.[^@\s]+$'),
+		website TEXT CHECK (website ~ '^[a-zA-Z][a-zA-Z0-9+.-]*:\S+$'),
+		lastIp INET,
+		wakeUpTime TIME,
+		sessionTimeout INTERVAL
+	);`, Flags{})
+}
+
+func TestTypeMappingsConfig(t *testing.T) {
+	options, err := loadOptions("tests/testdata/string_formats_config.yaml")
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+
+	testOpenAPISpecToSQL(t, "tests/testdata/string_formats.yaml", `
+	CREATE EXTENSION IF NOT EXISTS citext;
+
+	CREATE TABLE IF NOT EXISTS accounts (
+		id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+		email CITEXT NOT NULL CHECK (email ~ '^[^@\s]+@[^@\s]+\.[^@\s]+$'),
+		website TEXT CHECK (website ~ '^[a-zA-Z][a-zA-Z0-9+.-]*:\S+$'),
+		lastIp TEXT,
+		wakeUpTime TIME,
+		sessionTimeout INTERVAL
+	);`, Flags{options: options})
+}
+
+func TestJSONStorage(t *testing.T) {
+	testOpenAPISpecToSQL(t, "tests/testdata/json_storage.yaml", `
+	CREATE TABLE IF NOT EXISTS products (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		attributes JSONB,
+		labels JSONB,
+		translations JSONB
+	);
+
+	CREATE INDEX IF NOT EXISTS products_attributes_idx ON products USING GIN (attributes);`, Flags{})
+}
+
+func TestJSONStorageConfig(t *testing.T) {
+	options, err := loadOptions("tests/testdata/json_storage_config.yaml")
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+
+	// A GIN index cannot be built on a JSON column
+	_, err = generateSQL(t, "tests/testdata/json_storage.yaml", Flags{options: options})
+	if err == nil || !strings.Contains(err.Error(), "x-searchable") {
+		t.Errorf("Expected an x-searchable error, got %v", err)
+	}
+}
+
+func TestNumericPrecision(t *testing.T) {
+	expectedSQL := `
+	CREATE TABLE IF NOT EXISTS invoices (
+		amount NUMERIC(12,2) CHECK (amount >= 0 AND amount <= 9999999999.99),
+		rate NUMERIC(7,4),
+		discount NUMERIC(5,2) CHECK (discount <= 100 AND mod(discount, 0.05) = 0),
+		weight NUMERIC CHECK (mod(weight, 0.001) = 0),
+		quantity INTEGER CHECK (mod(quantity, 5) = 0)
+	);`
+	testOpenAPISpecToSQL(t, "tests/testdata/numeric_precision.yaml", expectedSQL, Flags{})
+}
+
+func TestValidationKeywords(t *testing.T) {
+	testOpenAPISpecToSQL(t, "tests/testdata/validation_keywords.yaml", `
+	CREATE TABLE IF NOT EXISTS reviews (
+		rating INTEGER CHECK (rating IN (1, 2, 3, 4, 5)),
+		score NUMERIC CHECK (score > 0 AND score < 10),
+		version INTEGER CHECK (version = 2),
+		kind TEXT CHECK (kind = 'it''s a review'),
+		published BOOLEAN CHECK (published = TRUE)
+	);`, Flags{})
+
+	// OpenAPI 3.0 exclusive bounds are booleans
+	testOpenAPISpecToSQL(t, "tests/testdata/validation_keywords_3_0.yaml", `
+	CREATE TABLE IF NOT EXISTS reviews (
+		score NUMERIC CHECK (score <= 10 AND score > 0)
+	);`, Flags{})
+}
+
+func TestInvalidValues(t *testing.T) {
+	// Enum and const values which are not of the column type are rejected
+	testSchemaErrors(t, "tests/testdata/invalid_values.yaml")
+}
+
+func TestLiterals(t *testing.T) {
+	testOpenAPISpecToSQL(t, "tests/testdata/literals.yaml", `
+	CREATE TYPE quote_mood AS ENUM ('happy', 'it''s fine');
+
+	CREATE TABLE IF NOT EXISTS quotes (
+		author TEXT CHECK (author ~ '^[A-Za-z'' ]+$') DEFAULT 'O''Brien',
+		mood quote_mood DEFAULT 'it''s fine',
+		active BOOLEAN DEFAULT TRUE,
+		likes INTEGER CHECK (likes >= 0) DEFAULT 0,
+		tags TEXT[] DEFAULT ARRAY['famous', 'o''clock'],
+		metadata JSONB DEFAULT '{"source":"book"}',
+		publishedOn DATE DEFAULT '2023-01-01'
+	);`, Flags{})
+}
+
+func TestInvalidDefaults(t *testing.T) {
+	testSchemaErrors(t, "tests/testdata/invalid_default.yaml")
+}
+
+func TestComments(t *testing.T) {
+	expectedSQL := `
+	CREATE TABLE IF NOT EXISTS users (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		email TEXT,
+		login TEXT,
+		nickname TEXT,
+		password TEXT
+	);
+
+	COMMENT ON TABLE users IS 'User: A person who can sign in';
+
+	COMMENT ON COLUMN users.id IS 'readOnly: set by the database, excluded from INSERT parameters';
+
+	COMMENT ON COLUMN users.email IS 'Address used to sign in, it''s unique';
+
+	COMMENT ON COLUMN users.login IS 'DEPRECATED: Replaced by email';
+
+	COMMENT ON COLUMN users.nickname IS 'DEPRECATED';
+
+	COMMENT ON COLUMN users.password IS 'Password of the user; writeOnly: store a hash, excluded from SELECT queries';`
+	testOpenAPISpecToSQL(t, "tests/testdata/comments.yaml", expectedSQL, Flags{})
+}
+
+func TestColumnAccess(t *testing.T) {
+	testOpenAPISpecToSQL(t, "tests/testdata/column_access.yaml", `
+	CREATE TABLE IF NOT EXISTS accounts (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		email TEXT NOT NULL,
+		password TEXT NOT NULL,
+		settings JSONB
+	);
+
+	COMMENT ON COLUMN accounts.id IS 'readOnly: set by the database, excluded from INSERT parameters';
+
+	COMMENT ON COLUMN accounts.password IS 'writeOnly: store a hash, excluded from SELECT queries';`, Flags{})
+
+	doc := parseTestSpec(t, "tests/testdata/column_access.yaml")
+	schema, _ := doc.Components.Schemas.Get("Account")
+	table, err := dbSchema.BuildTableFromSchema("Account", schema.Schema(), dbSchema.Options{})
+	if err != nil {
+		t.Fatalf("Error building the table: %v", err)
+	}
+	if columns := strings.Join(table.InsertColumns(), ", "); columns != "email, password, settings" {
+		t.Errorf("Expected the INSERT columns email, password, settings, got %s", columns)
+	}
+	if columns := strings.Join(table.SelectColumns(), ", "); columns != "id, email, settings" {
+		t.Errorf("Expected the SELECT columns id, email, settings, got %s", columns)
+	}
+
+	// The generated queries take the INSERT columns as parameters and return the SELECT columns
+	compareSQL(t, `
+	-- name: InsertAccounts :one
+	INSERT INTO accounts (email, password, settings)
+	VALUES ($1, $2, $3)
+	RETURNING id, email, settings;
+
+	-- name: UpdateAccounts :one
+	UPDATE accounts SET email = $2, password = $3, settings = $4
+	WHERE id = $1
+	RETURNING id, email, settings;`, strings.Join(fromComponentsToQueries([]dbSchema.Table{*table}), "\n\n"))
+}
+
+func TestFullTextSearch(t *testing.T) {
+	testOpenAPISpecToSQL(t, "tests/testdata/fulltext_search.yaml", `
+	CREATE TABLE IF NOT EXISTS blog_posts (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		title TEXT NOT NULL,
+		body TEXT,
+		slug TEXT GENERATED ALWAYS AS (lower(replace(title, ' ', '-'))) STORED,
+		wordCount INTEGER GENERATED ALWAYS AS (array_length(regexp_split_to_array(body, '\s+'), 1)) STORED,
+		search_vector TSVECTOR GENERATED ALWAYS AS (to_tsvector('english', coalesce(title, '') || ' ' || coalesce(body, ''))) STORED
+	);
+
+	CREATE INDEX IF NOT EXISTS blog_posts_search_vector_idx ON blog_posts USING GIN (search_vector);`, Flags{})
+
+	doc := parseTestSpec(t, "tests/testdata/fulltext_search.yaml")
+	tableDefinitions, err := buildTables(doc.Components, Flags{})
+	if err != nil {
+		t.Fatalf("Error building the tables: %v", err)
+	}
+	queries := fromComponentsToQueries(tableDefinitions)
+	expectedQuery := `-- name: SearchBlogPosts :many
+SELECT id, title, body, slug, wordCount FROM blog_posts
+WHERE search_vector @@ websearch_to_tsquery('english', $1)
+ORDER BY ts_rank(search_vector, websearch_to_tsquery('english', $1)) DESC;`
+	if len(queries) != 3 || queries[2] != expectedQuery {
+		t.Errorf("Expected the query:\n%s\ngot:\n%v", expectedQuery, queries)
+	} else if _, err = pg_query.Parse(queries[2]); err != nil {
+		t.Errorf("Invalid search query: %v", err)
+	}
+
+	// Generated columns are not INSERT parameters
+	schema, _ := doc.Components.Schemas.Get("BlogPost")
+	table, err := dbSchema.BuildTableFromSchema("BlogPost", schema.Schema(), dbSchema.Options{})
+	if err != nil {
+		t.Fatalf("Error building the table: %v", err)
+	}
+	if columns := strings.Join(table.InsertColumns(), ", "); columns != "id, title, body" {
+		t.Errorf("Expected the INSERT columns id, title, body, got %s", columns)
+	}
+}
+
+func TestColumnOverrides(t *testing.T) {
+	testOpenAPISpecToSQL(t, "tests/testdata/column_overrides.yaml", `
+	CREATE TABLE IF NOT EXISTS customers (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		last_name TEXT COLLATE "und-x-icu" NOT NULL,
+		country CHAR(2) DEFAULT 'FR',
+		notes TEXT STORAGE EXTERNAL COMPRESSION lz4,
+		preferences JSONB STORAGE MAIN,
+		code TEXT DEFAULT upper(md5(random()::text))
+	);`, Flags{})
+}
+
+func TestInvalidColumnOverrides(t *testing.T) {
+	// Overrides are rejected when invalid or when they add SQL around the column
+	testSchemaErrors(t, "tests/testdata/invalid_column_overrides.yaml")
+}
+
+func TestIdStrategies(t *testing.T) {
+	testOpenAPISpecToSQL(t, "tests/testdata/id_strategies.yaml", `
+	CREATE TABLE IF NOT EXISTS orders (
+		id INTEGER GENERATED ALWAYS AS IDENTITY NOT NULL PRIMARY KEY
+	);
+
+	CREATE TABLE IF NOT EXISTS invoices (
+		id BIGSERIAL NOT NULL PRIMARY KEY
+	);
+
+	CREATE TABLE IF NOT EXISTS events (
+		id UUID NOT NULL PRIMARY KEY DEFAULT uuidv7()
+	);
+
+	CREATE TABLE IF NOT EXISTS countries (
+		id INTEGER PRIMARY KEY,
+		ticketNumber BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL
+	);`, Flags{})
+}
+
+func TestIdStrategyConfig(t *testing.T) {
+	options, err := loadOptions("tests/testdata/id_strategies_config.yaml")
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+
+	testOpenAPISpecToSQL(t, "tests/testdata/native_arrays.yaml", `
+	CREATE OR REPLACE FUNCTION array_has_unique_items(items anyarray) RETURNS boolean
+	LANGUAGE sql IMMUTABLE AS $$
+		SELECT cardinality(items) = (SELECT count(DISTINCT item) FROM unnest(items) AS item)
+	$$;
+
+	CREATE TABLE IF NOT EXISTS pets (
+		id SERIAL NOT NULL PRIMARY KEY,
+		photoUrls TEXT[] CHECK (cardinality(photoUrls) >= 1 AND cardinality(photoUrls) <= 10),
+		scores BIGINT[],
+		tags TEXT[] CHECK (array_has_unique_items(tags)),
+		attributes JSONB
+	);`, Flags{options: options})
+}
+
 func TestIdCreatedAtUpdatedAt(t *testing.T) {
 	testOpenAPISpecToSQL(t, "tests/testdata/id_created_at_updated_at.yaml", `
+	CREATE OR REPLACE FUNCTION set_updated_at() RETURNS trigger
+	LANGUAGE plpgsql AS $$
+	BEGIN
+		NEW.updated_at = NOW();
+		RETURN NEW;
+	END;
+	$$;
+
 	CREATE TABLE IF NOT EXISTS users (
-		id BIGSERIAL NOT NULL PRIMARY KEY,
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
 		created_at TIMESTAMP NOT NULL DEFAULT NOW(),
 		updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
-		username TEXT
-	);`, Flags{})
+		username TEXT,
+		deleted_at TIMESTAMP
+	);
+
+	CREATE OR REPLACE TRIGGER users_set_updated_at BEFORE UPDATE ON users FOR EACH ROW EXECUTE FUNCTION set_updated_at();`, Flags{})
+}
+
+func TestNamingConfig(t *testing.T) {
+	options, err := loadOptions("tests/testdata/naming_config.yaml")
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+
+	testOpenAPISpecToSQL(t, "tests/testdata/naming.yaml", `
+	CREATE TYPE delivery_person_vehicle_type AS ENUM ('bike', 'van');
+
+	CREATE TABLE IF NOT EXISTS delivery_people (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		full_name TEXT NOT NULL,
+		vehicle_type delivery_person_vehicle_type
+	);
+
+	CREATE TABLE IF NOT EXISTS http_requests (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		request_url TEXT,
+		photo_urls TEXT[],
+		user_ids INTEGER[],
+		courier_id INTEGER REFERENCES delivery_people(id),
+		created_at TIMESTAMP NOT NULL DEFAULT NOW()
+	);`, Flags{options: options})
+}
+
+func TestSingularTableNames(t *testing.T) {
+	pluralize := false
+	options := dbSchema.Options{Naming: dbSchema.NamingOptions{Pluralize: &pluralize}}
+
+	testOpenAPISpecToSQL(t, "tests/testdata/naming.yaml", `
+	CREATE TYPE delivery_person_vehicleType AS ENUM ('bike', 'van');
+
+	CREATE TABLE IF NOT EXISTS delivery_person (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		fullName TEXT NOT NULL,
+		vehicleType delivery_person_vehicleType
+	);
+
+	CREATE TABLE IF NOT EXISTS http_request (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		requestURL TEXT,
+		photoURLs TEXT[],
+		userIDs INTEGER[],
+		courier_id INTEGER REFERENCES delivery_person(id),
+		createdAt TIMESTAMP
+	);`, Flags{options: options})
+}
+
+// prefixedNaming prefixes the default table names
+type prefixedNaming struct {
+	dbSchema.DefaultNaming
+}
+
+func (n prefixedNaming) TableName(schemaName string) string {
+	return "app_" + n.DefaultNaming.TableName(schemaName)
+}
+
+func (n prefixedNaming) Singular(tableName string) string {
+	return n.DefaultNaming.Singular(strings.TrimPrefix(tableName, "app_"))
+}
+
+func TestCustomNamingStrategy(t *testing.T) {
+	options := dbSchema.Options{NamingStrategy: prefixedNaming{}}
+
+	testOpenAPISpecToSQL(t, "tests/testdata/naming.yaml", `
+	CREATE TYPE delivery_person_vehicleType AS ENUM ('bike', 'van');
+
+	CREATE TABLE IF NOT EXISTS app_delivery_people (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		fullName TEXT NOT NULL,
+		vehicleType delivery_person_vehicleType
+	);
+
+	CREATE TABLE IF NOT EXISTS app_http_requests (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		requestURL TEXT,
+		photoURLs TEXT[],
+		userIDs INTEGER[],
+		courier_id INTEGER REFERENCES app_delivery_people(id),
+		createdAt TIMESTAMP
+	);`, Flags{options: options})
+}
+
+func TestAuditColumnsConfig(t *testing.T) {
+	options, err := loadOptions("tests/testdata/audit_columns_config.yaml")
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+
+	// updated_at is not a configured audit column, it is not set by a trigger
+	testOpenAPISpecToSQL(t, "tests/testdata/id_created_at_updated_at.yaml", `
+	CREATE TABLE IF NOT EXISTS users (
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ,
+		username VARCHAR(64) NOT NULL DEFAULT CURRENT_USER,
+		deleted_at TIMESTAMPTZ
+	);`, Flags{options: options})
+}
+
+func TestAuditColumnsOnUpdate(t *testing.T) {
+	options, err := loadOptions("tests/testdata/audit_on_update_config.yaml")
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+
+	// Only the timestamp onUpdate audit columns are set by a trigger
+	testOpenAPISpecToSQL(t, "tests/testdata/audit_on_update.yaml", `
+	CREATE OR REPLACE FUNCTION set_modified_at() RETURNS trigger
+	LANGUAGE plpgsql AS $$
+	BEGIN
+		NEW.modified_at = NOW();
+		RETURN NEW;
+	END;
+	$$;
+
+	CREATE TABLE IF NOT EXISTS documents (
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		title TEXT,
+		modified_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		modified_by TEXT DEFAULT CURRENT_USER
+	);
+
+	CREATE OR REPLACE TRIGGER documents_set_modified_at BEFORE UPDATE ON documents FOR EACH ROW EXECUTE FUNCTION set_modified_at();`, Flags{options: options})
 }
 
 func TestArrayOfRef(t *testing.T) {
 	testOpenAPISpecToSQL(t, "tests/testdata/array_of_ref.yaml", `
 	CREATE TABLE IF NOT EXISTS pets (
-		id BIGSERIAL NOT NULL PRIMARY KEY,
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
 		name TEXT NOT NULL,
-		tag_id INTEGER REFERENCES tags(id)
+		tag_id BIGINT REFERENCES tags(id)
 	);
 
 	CREATE TABLE IF NOT EXISTS tags (
-		id BIGSERIAL NOT NULL PRIMARY KEY,
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
 		name TEXT
 	);`, Flags{})
 }
 
+func TestEntityReferences(t *testing.T) {
+	testOpenAPISpecToSQL(t, "tests/testdata/entity_references.yaml", `
+	CREATE TABLE IF NOT EXISTS users (
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY
+	);
+
+	CREATE TABLE IF NOT EXISTS teams (
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY
+	);
+
+	CREATE TABLE IF NOT EXISTS projects (
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		owner_id BIGINT REFERENCES users(id),
+		member_id BIGINT REFERENCES users(id),
+		team_id BIGINT NOT NULL REFERENCES teams(id),
+		address JSONB
+	);`, Flags{})
+	// Foreign keys have the type of the referenced id, serial ids are referenced with their integer type
+	testOpenAPISpecToSQL(t, "tests/testdata/uuid_references.yaml", `
+	CREATE TABLE IF NOT EXISTS events (
+		id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid()
+	);
+
+	CREATE TABLE IF NOT EXISTS venues (
+		id SERIAL NOT NULL PRIMARY KEY
+	);
+
+	CREATE TABLE IF NOT EXISTS tickets (
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		event_id UUID REFERENCES events(id),
+		venue_id INTEGER REFERENCES venues(id)
+	);`, Flags{})
+}
+
+func TestEntityReferenceWithoutId(t *testing.T) {
+	// A foreign key needs an id to reference
+	if sql, err := generateSQL(t, "tests/testdata/entity_reference_without_id.yaml", Flags{}); err == nil {
+		t.Errorf("Expected an error, got:\n%s", sql)
+	}
+}
+
+func TestReservedWordReferences(t *testing.T) {
+	// Referenced and inherited tables are quoted part by part
+	testOpenAPISpecToSQL(t, "tests/testdata/reserved_references.yaml", `
+	CREATE SCHEMA IF NOT EXISTS auth;
+
+	CREATE TABLE IF NOT EXISTS auth."user" (
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		name TEXT
+	);
+
+	CREATE TABLE IF NOT EXISTS admins (
+		level INTEGER
+	) INHERITS (auth."user");
+
+	CREATE TABLE IF NOT EXISTS comments (
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		author_id BIGINT REFERENCES auth."user"(id)
+	);`, Flags{})
+
+	apiSpec, err := os.ReadFile("tests/testdata/reserved_references.yaml")
+	if err != nil {
+		t.Fatalf("Error reading OpenAPI spec: %v", err)
+	}
+	dumpSQL, err := os.ReadFile("tests/testdata/reserved_references_dump.sql")
+	if err != nil {
+		t.Fatalf("Error reading database dump: %v", err)
+	}
+
+	_, alterStatements, err := compareWithDatabaseDump(apiSpec, string(dumpSQL), dbSchema.Options{})
+	if err != nil {
+		t.Fatalf("Error comparing with database dump: %v", err)
+	}
+
+	compareSQL(t, `
+	ALTER TABLE comments ADD CONSTRAINT comments_author_id_fkey FOREIGN KEY (author_id) REFERENCES auth."user"(id);`, strings.Join(alterStatements, "\n"))
+}
+
 func TestDefaultValues(t *testing.T) {
 	testOpenAPISpecToSQL(t, "tests/testdata/default_values.yaml", `
     CREATE TABLE IF NOT EXISTS users (
-        id BIGSERIAL NOT NULL PRIMARY KEY,
+        id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
         username TEXT DEFAULT 'anonymous',
-        signup_date DATE DEFAULT 2023-01-01
+        signup_date DATE DEFAULT '2023-01-01'
     );`, Flags{})
 }
 
@@ -228,23 +948,78 @@ func TestEnumSupport(t *testing.T) {
     );`, Flags{})
 }
 
+func TestEnumStrategies(t *testing.T) {
+	testOpenAPISpecToSQL(t, "tests/testdata/enum_strategies.yaml", `
+	CREATE TYPE currency AS ENUM ('EUR', 'USD');
+
+	CREATE TABLE IF NOT EXISTS priorities (
+		id TEXT NOT NULL PRIMARY KEY
+	);
+
+	INSERT INTO priorities (id) VALUES ('low'), ('high') ON CONFLICT DO NOTHING;
+
+	CREATE TYPE order_status AS ENUM ('pending', 'shipped');
+
+	CREATE TABLE IF NOT EXISTS orders (
+		status order_status,
+		channel TEXT CHECK (channel IN ('web', 'store')),
+		currency currency,
+		priority TEXT REFERENCES priorities(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS refunds (
+		currency currency,
+		priority TEXT REFERENCES priorities(id)
+	);`, Flags{})
+}
+
+func TestDomains(t *testing.T) {
+	testOpenAPISpecToSQL(t, "tests/testdata/domains.yaml", `
+	CREATE DOMAIN email AS TEXT CHECK (char_length(VALUE) <= 254 AND VALUE ~ '^[^@\s]+@[^@\s]+\.[^@\s]+$');
+
+	CREATE DOMAIN positive_amount AS NUMERIC(9,2) CHECK (VALUE <= 1000000 AND VALUE > 0);
+
+	CREATE DOMAIN business_date AS DATE;
+
+	CREATE TABLE IF NOT EXISTS customers (
+		email email NOT NULL,
+		balance positive_amount
+	);
+
+	CREATE TABLE IF NOT EXISTS payments (
+		amount positive_amount,
+		paidOn business_date
+	);`, Flags{})
+}
+
+func TestBuiltinDomainNames(t *testing.T) {
+	// Domains named like a built-in type, e.g. uuid or date, are rejected
+	doc := parseTestSpec(t, "tests/testdata/builtin_domain_names.yaml")
+	for _, name := range []string{"Event", "Holiday"} {
+		schema, _ := doc.Components.Schemas.Get(name)
+		if _, err := dbSchema.BuildTableFromSchema(name, schema.Schema(), dbSchema.Options{}); err == nil {
+			t.Errorf("Expected an error building the table of %s", name)
+		}
+	}
+}
+
 func TestReadmeExample(t *testing.T) {
 	testOpenAPISpecToSQL(t, "tests/testdata/readme_example.yaml", `
 	CREATE TABLE IF NOT EXISTS pets (
-        id BIGSERIAL NOT NULL PRIMARY KEY,
-        category_id INTEGER REFERENCES categories(id),
+        id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+        category_id BIGINT REFERENCES categories(id),
         name TEXT NOT NULL,
-        photoUrls JSON NOT NULL,
-        tag_id INTEGER REFERENCES tags(id)
+        photoUrls TEXT[] NOT NULL,
+        tag_id BIGINT REFERENCES tags(id)
 	);
 
 	CREATE TABLE IF NOT EXISTS categories (
-		id BIGSERIAL NOT NULL PRIMARY KEY,
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
 		name TEXT
 	);
 
 	CREATE TABLE IF NOT EXISTS tags (
-		id BIGSERIAL NOT NULL PRIMARY KEY,
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
 		name TEXT
 	);`, Flags{})
 }
@@ -268,3 +1043,196 @@ func TestWriteInFolder(t *testing.T) {
 		t.Errorf("Failed to remove folder: %v", err)
 	}
 }
+
+func TestCheckSchemaDriftUpToDate(t *testing.T) {
+	apiSpec, err := os.ReadFile("tests/testdata/constraints.yaml")
+	if err != nil {
+		t.Fatalf("Error reading OpenAPI spec: %v", err)
+	}
+	committedSQL, err := os.ReadFile("tests/testdata/schema_check_up_to_date.sql")
+	if err != nil {
+		t.Fatalf("Error reading committed SQL: %v", err)
+	}
+
+	differences, sameStatements, err := checkSchemaDrift(apiSpec, string(committedSQL), Flags{})
+	if err != nil {
+		t.Fatalf("Error checking schema drift: %v", err)
+	}
+	if !sameStatements || len(differences) != 0 {
+		t.Errorf("Expected no drift, got statements match %v and differences %v", sameStatements, differences)
+	}
+}
+
+func TestCheckSchemaDrift(t *testing.T) {
+	apiSpec, err := os.ReadFile("tests/testdata/constraints.yaml")
+	if err != nil {
+		t.Fatalf("Error reading OpenAPI spec: %v", err)
+	}
+	committedSQL, err := os.ReadFile("tests/testdata/schema_check_drift.sql")
+	if err != nil {
+		t.Fatalf("Error reading committed SQL: %v", err)
+	}
+
+	differences, sameStatements, err := checkSchemaDrift(apiSpec, string(committedSQL), Flags{})
+	if err != nil {
+		t.Fatalf("Error checking schema drift: %v", err)
+	}
+	if sameStatements {
+		t.Errorf("Expected different statements")
+	}
+
+	expected := []string{
+		"column productid: check differs (expected productid >= 1 AND productid <= 1000, found productid >= 1 AND productid <= 500)",
+		"column productname: type differs (expected text, found varchar(100))",
+		"column productname: not null differs (expected false, found true)",
+		"column productname: check differs (expected char_length(productname) >= 1 AND char_length(productname) <= 100, found none)",
+		"column productcode: missing",
+		"column discontinued: unexpected",
+		"table legacy_products: unexpected",
+	}
+
+	var actual []string
+	for _, difference := range differences {
+		actual = append(actual, difference.String())
+	}
+
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected drift report.\nGot:\n%s\n\nWanted:\n%s", strings.Join(actual, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestCheckSchemaDriftOutsideTables(t *testing.T) {
+	apiSpec, err := os.ReadFile("tests/testdata/schema_check_statements.yaml")
+	if err != nil {
+		t.Fatalf("Error reading OpenAPI spec: %v", err)
+	}
+	generatedSQL, err := generateSQL(t, "tests/testdata/schema_check_statements.yaml", Flags{})
+	if err != nil {
+		t.Fatalf("Error transforming OpenAPI to SQL: %v", err)
+	}
+
+	// Changes which are not seen by the table by table comparison, nor by the fingerprints ignoring constants
+	edits := map[string][2]string{
+		"domain check bounds":         {"VALUE <= 1000", "VALUE <= 500"},
+		"domain type modifiers":       {"AS NUMERIC(6,2)", "AS NUMERIC(8,2)"},
+		"composite type modifiers":    {"value NUMERIC(6,2)", "value NUMERIC(8,2)"},
+		"lookup table seed values":    {"('low'), ('high')", "('low'), ('medium')"},
+		"generated column expression": {"upper(id::text)", "lower(id::text)"},
+	}
+
+	for name, edit := range edits {
+		if !strings.Contains(generatedSQL, edit[0]) {
+			t.Fatalf("%s: %q not found in the generated SQL:\n%s", name, edit[0], generatedSQL)
+		}
+		committedSQL := strings.Replace(generatedSQL, edit[0], edit[1], 1)
+
+		_, sameStatements, err := checkSchemaDrift(apiSpec, committedSQL, Flags{})
+		if err != nil {
+			t.Fatalf("%s: error checking schema drift: %v", name, err)
+		}
+		if sameStatements {
+			t.Errorf("%s: expected different statements", name)
+		}
+	}
+
+	_, sameStatements, err := checkSchemaDrift(apiSpec, generatedSQL, Flags{})
+	if err != nil {
+		t.Fatalf("Error checking schema drift: %v", err)
+	}
+	if !sameStatements {
+		t.Errorf("Expected the generated SQL to be up to date")
+	}
+}
+
+func TestCompareConstraintNames(t *testing.T) {
+	apiSpec, err := os.ReadFile("tests/testdata/constraint_names.yaml")
+	if err != nil {
+		t.Fatalf("Error reading OpenAPI spec: %v", err)
+	}
+	dumpSQL, err := os.ReadFile("tests/testdata/constraint_names_dump.sql")
+	if err != nil {
+		t.Fatalf("Error reading database dump: %v", err)
+	}
+
+	differences, alterStatements, err := compareWithDatabaseDump(apiSpec, string(dumpSQL), dbSchema.Options{})
+	if err != nil {
+		t.Fatalf("Error comparing with database dump: %v", err)
+	}
+
+	expected := []string{
+		"column email: unique differs (expected false, found true)",
+		"column ownerid: references differs (expected none, found users)",
+		"column balance: check differs (expected balance >= 10, found balance >= 0::numeric)",
+		"column slug: generated differs (expected lower(email), found none)",
+		"column label: generated differs (expected upper(email), found lower(email))",
+		"table accounts: check differs (expected none, found balance <= limitamount)",
+	}
+
+	var actual []string
+	for _, difference := range differences {
+		actual = append(actual, difference.String())
+	}
+
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected comparison report.\nGot:\n%s\n\nWanted:\n%s", strings.Join(actual, "\n"), strings.Join(expected, "\n"))
+	}
+
+	// Constraints are dropped with the names found in the dump
+	compareSQL(t, `
+	ALTER TABLE accounts DROP CONSTRAINT account_email_uniq;
+	ALTER TABLE accounts DROP CONSTRAINT fk_owner;
+	ALTER TABLE accounts DROP CONSTRAINT positive_balance;
+	ALTER TABLE accounts ADD CONSTRAINT accounts_balance_check CHECK (balance >= 10);
+	ALTER TABLE accounts DROP COLUMN slug;
+	ALTER TABLE accounts ADD COLUMN slug TEXT GENERATED ALWAYS AS (lower(email)) STORED;
+	ALTER TABLE accounts DROP COLUMN label;
+	ALTER TABLE accounts ADD COLUMN label TEXT GENERATED ALWAYS AS (upper(email)) STORED;
+	ALTER TABLE accounts DROP CONSTRAINT balance_below_limit;`, strings.Join(alterStatements, "\n"))
+}
+
+func TestCompareWithDatabaseDump(t *testing.T) {
+	apiSpec, err := os.ReadFile("tests/testdata/readme_example.yaml")
+	if err != nil {
+		t.Fatalf("Error reading OpenAPI spec: %v", err)
+	}
+	dumpSQL, err := os.ReadFile("tests/testdata/readme_example_dump.sql")
+	if err != nil {
+		t.Fatalf("Error reading database dump: %v", err)
+	}
+
+	differences, alterStatements, err := compareWithDatabaseDump(apiSpec, string(dumpSQL), dbSchema.Options{})
+	if err != nil {
+		t.Fatalf("Error comparing with database dump: %v", err)
+	}
+
+	expected := []string{
+		"column category_id: type differs (expected bigint, found integer)",
+		"column name: type differs (expected text, found varchar(50))",
+		"column name: check differs (expected none, found char_length(name::text) >= 1)",
+		"column photourls: not null differs (expected true, found false)",
+		"column tag_id: missing",
+		"column status: unexpected",
+		"table tags: missing",
+	}
+
+	var actual []string
+	for _, difference := range differences {
+		actual = append(actual, difference.String())
+	}
+
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected comparison report.\nGot:\n%s\n\nWanted:\n%s", strings.Join(actual, "\n"), strings.Join(expected, "\n"))
+	}
+
+	compareSQL(t, `
+	CREATE TABLE IF NOT EXISTS tags (
+		id BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY,
+		name TEXT
+	);
+	ALTER TABLE pets ALTER COLUMN category_id TYPE bigint USING category_id::bigint;
+	ALTER TABLE pets ALTER COLUMN name TYPE text USING name::text;
+	ALTER TABLE pets DROP CONSTRAINT pets_name_check;
+	ALTER TABLE pets ALTER COLUMN photourls SET NOT NULL;
+	ALTER TABLE pets ADD COLUMN tag_id BIGINT REFERENCES tags(id);
+	ALTER TABLE pets DROP COLUMN status;`, strings.Join(alterStatements, "\n"))
+}
@@ -0,0 +1,35 @@
+CREATE TABLE public.users (
+    id bigint NOT NULL
+);
+
+ALTER TABLE public.users ALTER COLUMN id ADD GENERATED BY DEFAULT AS IDENTITY (
+    SEQUENCE NAME public.users_id_seq
+);
+
+CREATE TABLE public.accounts (
+    id bigint NOT NULL,
+    email text,
+    ownerid bigint,
+    balance numeric,
+    limitamount numeric,
+    slug text,
+    label text GENERATED ALWAYS AS (lower(email)) STORED,
+    CONSTRAINT positive_balance CHECK ((balance >= (0)::numeric)),
+    CONSTRAINT balance_below_limit CHECK ((balance <= limitamount))
+);
+
+ALTER TABLE public.accounts ALTER COLUMN id ADD GENERATED BY DEFAULT AS IDENTITY (
+    SEQUENCE NAME public.accounts_id_seq
+);
+
+ALTER TABLE ONLY public.users
+    ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.accounts
+    ADD CONSTRAINT accounts_primary PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.accounts
+    ADD CONSTRAINT account_email_uniq UNIQUE (email);
+
+ALTER TABLE ONLY public.accounts
+    ADD CONSTRAINT fk_owner FOREIGN KEY (ownerid) REFERENCES public.users(id);
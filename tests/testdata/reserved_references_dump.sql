@@ -0,0 +1,30 @@
+CREATE SCHEMA auth;
+
+CREATE TABLE auth."user" (
+    id bigint NOT NULL,
+    name text
+);
+
+ALTER TABLE auth."user" ALTER COLUMN id ADD GENERATED BY DEFAULT AS IDENTITY (
+    SEQUENCE NAME auth.user_id_seq
+);
+
+CREATE TABLE public.admins (
+    level integer
+)
+INHERITS (auth."user");
+
+CREATE TABLE public.comments (
+    id bigint NOT NULL,
+    author_id bigint
+);
+
+ALTER TABLE public.comments ALTER COLUMN id ADD GENERATED BY DEFAULT AS IDENTITY (
+    SEQUENCE NAME public.comments_id_seq
+);
+
+ALTER TABLE ONLY auth."user"
+    ADD CONSTRAINT user_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.comments
+    ADD CONSTRAINT comments_pkey PRIMARY KEY (id);
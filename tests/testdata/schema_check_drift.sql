@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS products (
+    productId INTEGER CHECK (productId >= 1 AND productId <= 500),
+    productName VARCHAR(100) NOT NULL,
+    productPrice NUMERIC CHECK (productPrice >= 0.01 AND productPrice <= 9999.99),
+    releaseDate DATE DEFAULT '2023-01-01',
+    discontinued BOOLEAN
+);
+
+CREATE TABLE IF NOT EXISTS legacy_products (
+    id BIGSERIAL NOT NULL PRIMARY KEY
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS products (
+    productId INTEGER CHECK (productId >= 1 AND productId <= 1000),
+    productName TEXT CHECK (char_length(productName) >= 1 AND char_length(productName) <= 100),
+    productPrice NUMERIC CHECK (productPrice >= 0.01 AND productPrice <= 9999.99),
+    productCode TEXT CHECK (productCode ~ '^[A-Z0-9]{10}$'),
+    releaseDate DATE DEFAULT '2023-01-01'
+);